@@ -0,0 +1,115 @@
+package sentrykit
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// reportedLeaks dedupes scope leak diagnostics so a persistent leak is
+// reported once per process instead of on every request
+var reportedLeaks sync.Map
+
+// kitGlobalContexts are set on the global scope by the kit after Init
+// (ReportRoutes, StartStatsReporter) and are not leaks
+var kitGlobalContexts = map[string]bool{"routes": true, "runtime_stats": true}
+
+// globalScopeBaseline is the global scope as Init left it, recorded in
+// debug mode for auditGlobalScope
+var globalScopeBaseline atomic.Pointer[scopeBaseline]
+
+// scopeBaseline is the data of the global scope after Init
+type scopeBaseline struct {
+	snapshot scopeSnapshot
+	user     bool
+}
+
+// recordGlobalScopeBaseline snapshots the global scope for the scope
+// isolation audit, which only runs in debug mode
+func recordGlobalScopeBaseline() {
+	if !debugEnabled() {
+		globalScopeBaseline.Store(nil)
+		return
+	}
+	event := sentry.CurrentHub().Scope().ApplyToEvent(&sentry.Event{Type: "transaction"}, nil, nil)
+	if event == nil {
+		return
+	}
+	globalScopeBaseline.Store(&scopeBaseline{
+		snapshot: snapshotEvent(event),
+		user:     !event.User.IsEmpty(),
+	})
+}
+
+// auditGlobalScope compares the global scope with its state after Init and
+// reports a diagnostic event when tags, contexts, extras or the user were
+// added or changed since, e.g. by a request writing to the global hub
+func auditGlobalScope(path string) {
+	baseline := globalScopeBaseline.Load()
+	if baseline == nil {
+		return
+	}
+	event := sentry.CurrentHub().Scope().ApplyToEvent(&sentry.Event{Type: "transaction"}, nil, nil)
+	if event == nil {
+		return
+	}
+
+	diff := diffScopes(baseline.snapshot, snapshotEvent(event))
+	var leakedTags []string
+	for key := range diff.TagsAdded {
+		leakedTags = append(leakedTags, key)
+	}
+	for key := range diff.TagsChanged {
+		leakedTags = append(leakedTags, key)
+	}
+	sort.Strings(leakedTags)
+	var leakedContexts []string
+	for _, key := range append(diff.ContextsAdded, diff.ContextsChanged...) {
+		if !kitGlobalContexts[key] {
+			leakedContexts = append(leakedContexts, key)
+		}
+	}
+	leakedExtra := append(diff.ExtraAdded, diff.ExtraChanged...)
+	userLeaked := !baseline.user && !event.User.IsEmpty()
+
+	if len(leakedTags) == 0 && len(leakedContexts) == 0 && len(leakedExtra) == 0 && !userLeaked {
+		return
+	}
+
+	reportScopeLeak("global_scope", map[string]interface{}{
+		"leaked_tags":     leakedTags,
+		"leaked_contexts": leakedContexts,
+		"leaked_extra":    leakedExtra,
+		"user_leaked":     userLeaked,
+		"detected_on":     path,
+	})
+}
+
+// reportScopeLeak sends a scope isolation diagnostic once per leak signature.
+// It uses a fresh scope so the report doesn't carry the leaked data itself.
+func reportScopeLeak(kind string, details map[string]interface{}) {
+	signature := kind
+	for _, key := range []string{"leaked_tags", "leaked_contexts", "leaked_extra"} {
+		if keys, ok := details[key].([]string); ok {
+			sorted := append([]string(nil), keys...)
+			sort.Strings(sorted)
+			for _, k := range sorted {
+				signature += "|" + k
+			}
+		}
+	}
+	if _, loaded := reportedLeaks.LoadOrStore(signature, struct{}{}); loaded {
+		return
+	}
+
+	hub := sentry.NewHub(sentry.CurrentHub().Client(), sentry.NewScope())
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetLevel(sentry.LevelWarning)
+		scope.SetTag("scope_audit", kind)
+		scope.SetContext("scope_audit", details)
+		scope.SetFingerprint([]string{"sentrykit-scope-leak", kind})
+		hub.CaptureMessage("Sentry scope leak detected: " + kind)
+	})
+}
@@ -0,0 +1,73 @@
+package sentrykit
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestAuditScopeIsolationComparesWithInitSnapshot(t *testing.T) {
+	transport := bindTestClient(t)
+	global := sentry.CurrentHub().Scope()
+
+	app := fiber.New()
+	app.Use(New(MiddlewareConfig{AuditScopeIsolation: true}))
+	app.Get("/", func(c fiber.Ctx) error { return c.SendString("ok") })
+
+	request := func() {
+		t.Helper()
+		if _, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	leaks := func() int {
+		var n int
+		for _, event := range transport.Events() {
+			if event.Tags["scope_audit"] == "global_scope" {
+				n++
+			}
+		}
+		return n
+	}
+
+	setDebugMode(true, nil)
+	t.Cleanup(func() {
+		setDebugMode(false, nil)
+		globalScopeBaseline.Store(nil)
+		reportedLeaks.Range(func(key, _ interface{}) bool {
+			reportedLeaks.Delete(key)
+			return true
+		})
+	})
+
+	// Set before the snapshot, as Init does with GlobalTags
+	global.SetTag("audit_test_before", "init")
+	t.Cleanup(func() { global.RemoveTag("audit_test_before") })
+	recordGlobalScopeBaseline()
+
+	// The kit's own global contexts aren't leaks
+	global.SetContext("runtime_stats", map[string]interface{}{"goroutines": 1})
+	t.Cleanup(func() { global.RemoveContext("runtime_stats") })
+
+	request()
+	if n := leaks(); n != 0 {
+		t.Fatalf("reported %d leaks for a clean global scope", n)
+	}
+
+	global.SetTag("audit_test_leaked", "request")
+	t.Cleanup(func() { global.RemoveTag("audit_test_leaked") })
+
+	setDebugMode(false, nil)
+	request()
+	if n := leaks(); n != 0 {
+		t.Fatalf("audit ran without debug mode")
+	}
+
+	setDebugMode(true, nil)
+	request()
+	if n := leaks(); n != 1 {
+		t.Fatalf("reported %d leaks, want 1", n)
+	}
+}
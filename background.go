@@ -0,0 +1,67 @@
+package sentrykit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// backgroundTasks holds the stop functions of goroutines started by Init
+var backgroundTasks struct {
+	mu    sync.Mutex
+	stops []func()
+}
+
+// addBackgroundTask registers a goroutine started by Init for stopping on Close
+func addBackgroundTask(stop func()) {
+	backgroundTasks.mu.Lock()
+	defer backgroundTasks.mu.Unlock()
+	backgroundTasks.stops = append(backgroundTasks.stops, stop)
+}
+
+// stopBackgroundTasks stops the goroutines started by Init
+func stopBackgroundTasks() {
+	backgroundTasks.mu.Lock()
+	defer backgroundTasks.mu.Unlock()
+	for _, stop := range backgroundTasks.stops {
+		stop()
+	}
+	backgroundTasks.stops = nil
+}
+
+// startTicker runs fn every interval in a goroutine until the returned
+// function is called
+func startTicker(interval time.Duration, fn func()) (stop func()) {
+	done := make(chan struct{})
+	var once sync.Once
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				fn()
+			}
+		}
+	}()
+
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// startPeriodicFlush flushes the hub's buffered events every interval, so
+// idle services don't hold events until the next request or shutdown
+func startPeriodicFlush(hub *sentry.Hub, interval time.Duration) (stop func()) {
+	return startTicker(interval, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultFlushTimeout)
+		defer cancel()
+		hub.FlushWithContext(ctx)
+	})
+}
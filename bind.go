@@ -0,0 +1,91 @@
+package sentrykit
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+)
+
+// Bind binds the request body into out like c.Bind().Body(out), inside a
+// "serialize" span. A bind failure is captured as a warning event with a
+// "bind" context describing the offending field or position and the body
+// (see body parse diagnostics in the README) and recorded as the request's
+// captured event, so it isn't reported again and error pages can show its
+// ID. The error is returned unchanged so the handler decides the response.
+func Bind(c fiber.Ctx, out interface{}) error {
+	contentType := string(c.Request().Header.ContentType())
+
+	span := sentry.StartSpan(c.UserContext(), "serialize", sentry.WithDescription("bind "+contentType))
+	defer span.Finish()
+
+	err := c.Bind().Body(out)
+	if err == nil {
+		span.Status = sentry.SpanStatusOK
+		return nil
+	}
+	span.Status = sentry.SpanStatusInvalidArgument
+
+	data := bindErrorContext(err)
+	for key, value := range bodyDiagnostics(func(name string) string { return c.Get(name) }, c.Body()) {
+		data[key] = value
+	}
+	data["target"] = fmt.Sprintf("%T", out)
+
+	hub := GetHubFromContext(c)
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetLevel(sentry.LevelWarning)
+		scope.SetTag("error_category", "bind")
+		scope.SetContext("bind", data)
+		scope.SetFingerprint([]string{"bind", c.Route().Path, fmt.Sprint(data["field"])})
+		if eventID := hub.CaptureException(err); eventID != nil {
+			c.Locals(capturedEventKey, eventID)
+		}
+	})
+
+	return err
+}
+
+// bindErrorContext describes where decoding failed
+func bindErrorContext(err error) map[string]interface{} {
+	var (
+		syntaxErr    *json.SyntaxError
+		typeErr      *json.UnmarshalTypeError
+		xmlSyntaxErr *xml.SyntaxError
+	)
+
+	switch {
+	case errors.As(err, &typeErr):
+		data := map[string]interface{}{
+			"kind":   "type_mismatch",
+			"field":  typeErr.Field,
+			"got":    typeErr.Value,
+			"offset": typeErr.Offset,
+		}
+		if typeErr.Type != nil {
+			data["expected"] = typeErr.Type.String()
+		}
+		return data
+	case errors.As(err, &syntaxErr):
+		return map[string]interface{}{
+			"kind":   "syntax",
+			"offset": syntaxErr.Offset,
+		}
+	case errors.As(err, &xmlSyntaxErr):
+		return map[string]interface{}{
+			"kind": "syntax",
+			"line": xmlSyntaxErr.Line,
+		}
+	case errors.Is(err, fiber.ErrUnprocessableEntity):
+		return map[string]interface{}{
+			"kind": "unsupported_content_type",
+		}
+	default:
+		return map[string]interface{}{
+			"kind": "invalid",
+		}
+	}
+}
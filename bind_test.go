@@ -0,0 +1,51 @@
+package sentrykit
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestBindFailureCapturedOnce(t *testing.T) {
+	transport := bindTestClient(t)
+
+	var eventID interface{}
+	app := fiber.New(fiber.Config{ErrorHandler: NewErrorHandler(ErrorHandlerConfig{MinStatus: 400})})
+	app.Use(New())
+	app.Post("/", func(c fiber.Ctx) error {
+		var body struct {
+			Quantity int `json:"quantity"`
+		}
+		err := Bind(c, &body)
+		eventID = c.Locals(capturedEventKey)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		return c.SendString("ok")
+	})
+
+	req := httptest.NewRequest(fiber.MethodPost, "/", strings.NewReader(`{"quantity": "two"}`))
+	req.Header.Set("Content-Type", "application/json")
+	if _, err := app.Test(req); err != nil {
+		t.Fatal(err)
+	}
+
+	var failures []*sentry.Event
+	for _, event := range transport.Events() {
+		if event.Type != "transaction" {
+			failures = append(failures, event)
+		}
+	}
+	if len(failures) != 1 {
+		t.Fatalf("captured %d events for one bind failure, want 1", len(failures))
+	}
+	if failures[0].Tags["error_category"] != "bind" {
+		t.Errorf("event tags = %v, want the bind event", failures[0].Tags)
+	}
+	if id, ok := eventID.(*sentry.EventID); !ok || *id != failures[0].EventID {
+		t.Errorf("captured event local = %v, want %s", eventID, failures[0].EventID)
+	}
+}
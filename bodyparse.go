@@ -0,0 +1,86 @@
+package sentrykit
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// bodyPreviewBytes is how many leading body bytes body parse diagnostics
+// include
+const bodyPreviewBytes = 64
+
+// isBodyParseError reports whether err comes from decoding a request body:
+// a JSON or XML decoder error, a truncated body, or a Fiber bind error
+func isBodyParseError(err error) bool {
+	var (
+		syntaxErr    *json.SyntaxError
+		typeErr      *json.UnmarshalTypeError
+		xmlSyntaxErr *xml.SyntaxError
+		fiberErr     *fiber.Error
+	)
+	switch {
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr), errors.As(err, &xmlSyntaxErr):
+		return true
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	case errors.As(err, &fiberErr):
+		// Bind without Should wraps the decoder error in a 400
+		return fiberErr.Code == fiber.StatusBadRequest && strings.HasPrefix(fiberErr.Message, "Bad request: ")
+	}
+	return false
+}
+
+// bodyDiagnostics describes a request body that failed to parse: the
+// content type, the declared (Content-Length) and actual length, and the
+// first bytes as hex. body is the decoded body, so the lengths of
+// compressed bodies aren't compared. Letters and digits are masked in the
+// preview, keeping the punctuation, whitespace, control and non-ASCII bytes
+// that usually break parsers (BOMs, NULs, invalid UTF-8, truncated
+// structure) without exposing the payload.
+func bodyDiagnostics(header func(name string) string, body []byte) map[string]interface{} {
+	data := map[string]interface{}{
+		"content_type": strings.Clone(header("Content-Type")),
+		"body_size":    len(body),
+	}
+	encoding := header("Content-Encoding")
+	if encoding != "" {
+		data["content_encoding"] = strings.Clone(encoding)
+	}
+	if declared, err := strconv.Atoi(header("Content-Length")); err == nil {
+		data["content_length"] = declared
+		if encoding == "" {
+			data["length_mismatch"] = declared != len(body)
+		}
+	}
+
+	head := body[:min(len(body), bodyPreviewBytes)]
+	if len(head) > 0 {
+		data["body_head_hex"] = fmt.Sprintf("% x", sanitizeBodyHead(head))
+		data["body_head_truncated"] = len(body) > len(head)
+	}
+	return data
+}
+
+// sanitizeBodyHead returns a copy of head with ASCII letters masked as 'x'
+// and digits as '0'
+func sanitizeBodyHead(head []byte) []byte {
+	sanitized := make([]byte, len(head))
+	for i, b := range head {
+		switch {
+		case b >= 'a' && b <= 'z', b >= 'A' && b <= 'Z':
+			sanitized[i] = 'x'
+		case b >= '0' && b <= '9':
+			sanitized[i] = '0'
+		default:
+			sanitized[i] = b
+		}
+	}
+	return sanitized
+}
@@ -0,0 +1,127 @@
+package sentrykit
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// BreadcrumbOptions sets the level, type and time of a breadcrumb added
+// with AddBreadcrumb or AddBreadcrumbFromContext
+type BreadcrumbOptions struct {
+	// Level distinguishes e.g. retries (warning) from progress (default: info)
+	Level sentry.Level
+
+	// Type controls how Sentry renders the breadcrumb, e.g. "http", "query"
+	// or "error" (default: "default")
+	Type string
+
+	// Timestamp is when it happened, for breadcrumbs recorded after the
+	// fact (default: now)
+	Timestamp time.Time
+}
+
+// newBreadcrumb builds a breadcrumb from the helper arguments
+func newBreadcrumb(message, category string, data map[string]interface{}, options []BreadcrumbOptions) *sentry.Breadcrumb {
+	var opts BreadcrumbOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.Level == "" {
+		opts.Level = sentry.LevelInfo
+	}
+	if opts.Timestamp.IsZero() {
+		opts.Timestamp = now()
+	}
+
+	return &sentry.Breadcrumb{
+		Type:      opts.Type,
+		Message:   message,
+		Category:  category,
+		Data:      data,
+		Level:     opts.Level,
+		Timestamp: opts.Timestamp,
+	}
+}
+
+// BreadcrumbFilter modifies a breadcrumb or drops it by returning nil
+type BreadcrumbFilter func(breadcrumb *sentry.Breadcrumb, hint *sentry.BreadcrumbHint) *sentry.Breadcrumb
+
+// breadcrumbFilters is the kit-level filter chain applied to every breadcrumb
+var breadcrumbFilters struct {
+	mu      sync.RWMutex
+	filters []BreadcrumbFilter
+}
+
+// AddBreadcrumbFilter appends a filter to the breadcrumb filter chain.
+// Filters run in registration order after Config.BeforeBreadcrumb.
+func AddBreadcrumbFilter(filter BreadcrumbFilter) {
+	breadcrumbFilters.mu.Lock()
+	defer breadcrumbFilters.mu.Unlock()
+	breadcrumbFilters.filters = append(breadcrumbFilters.filters, filter)
+}
+
+// DropBreadcrumbs returns a filter that drops breadcrumbs of the given categories
+func DropBreadcrumbs(categories ...string) BreadcrumbFilter {
+	drop := make(map[string]struct{}, len(categories))
+	for _, category := range categories {
+		drop[category] = struct{}{}
+	}
+
+	return func(breadcrumb *sentry.Breadcrumb, _ *sentry.BreadcrumbHint) *sentry.Breadcrumb {
+		if _, ok := drop[breadcrumb.Category]; ok {
+			return nil
+		}
+		return breadcrumb
+	}
+}
+
+// SampleBreadcrumbs returns a filter that keeps only a fraction (0.0 - 1.0)
+// of the breadcrumbs of a category
+func SampleBreadcrumbs(category string, rate float64) BreadcrumbFilter {
+	return func(breadcrumb *sentry.Breadcrumb, _ *sentry.BreadcrumbHint) *sentry.Breadcrumb {
+		if breadcrumb.Category == category && rand.Float64() >= rate {
+			return nil
+		}
+		return breadcrumb
+	}
+}
+
+// breadcrumbBudget returns a scope event processor keeping the most recent
+// max breadcrumbs and recording how many were dropped
+func breadcrumbBudget(max int) sentry.EventProcessor {
+	return func(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+		if dropped := len(event.Breadcrumbs) - max; dropped > 0 {
+			event.Breadcrumbs = event.Breadcrumbs[dropped:]
+			if event.Extra == nil {
+				event.Extra = make(map[string]interface{})
+			}
+			event.Extra["truncated_breadcrumbs"] = dropped
+		}
+		return event
+	}
+}
+
+// beforeBreadcrumb builds the BeforeBreadcrumb hook running the configured
+// hook followed by the kit-level filter chain
+func beforeBreadcrumb(hook BreadcrumbFilter) BreadcrumbFilter {
+	return func(breadcrumb *sentry.Breadcrumb, hint *sentry.BreadcrumbHint) *sentry.Breadcrumb {
+		if hook != nil {
+			if breadcrumb = hook(breadcrumb, hint); breadcrumb == nil {
+				return nil
+			}
+		}
+
+		breadcrumbFilters.mu.RLock()
+		defer breadcrumbFilters.mu.RUnlock()
+
+		for _, filter := range breadcrumbFilters.filters {
+			if breadcrumb = filter(breadcrumb, hint); breadcrumb == nil {
+				return nil
+			}
+		}
+		return breadcrumb
+	}
+}
@@ -0,0 +1,92 @@
+package sentrykit
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// Build metadata, typically set at link time:
+//
+//	go build -ldflags "\
+//	    -X github.com/purwadarozatun/go-sentry-fiber-3.GitSHA=$(git rev-parse HEAD) \
+//	    -X github.com/purwadarozatun/go-sentry-fiber-3.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ) \
+//	    -X github.com/purwadarozatun/go-sentry-fiber-3.Builder=ci"
+//
+// When set, events carry them without calling SetBuildInfo.
+var (
+	GitSHA    string
+	BuildTime string
+	Builder   string
+)
+
+// buildInfo is the metadata attached to events
+type buildInfo struct {
+	gitSHA    string
+	buildTime string
+	builder   string
+}
+
+// currentBuild holds the metadata passed to SetBuildInfo
+var currentBuild atomic.Pointer[buildInfo]
+
+// registerBuildProcessor adds the build metadata processor once
+var registerBuildProcessor sync.Once
+
+// SetBuildInfo tags all events with the build's git SHA ("build_sha"),
+// build time ("build_time") and builder ("builder"), and uses the git SHA
+// as the event dist unless Config.Dist is set. It overrides the
+// link-time GitSHA, BuildTime and Builder variables. Empty values are
+// omitted.
+func SetBuildInfo(gitSHA, buildTime, builder string) {
+	currentBuild.Store(&buildInfo{
+		gitSHA:    gitSHA,
+		buildTime: buildTime,
+		builder:   builder,
+	})
+	registerBuildInfo()
+}
+
+// registerBuildInfo adds the processor attaching build metadata. It is a
+// global processor, so it applies to transactions and all clients.
+func registerBuildInfo() {
+	registerBuildProcessor.Do(func() {
+		sentry.AddGlobalEventProcessor(applyBuildInfo)
+	})
+}
+
+// linkedBuildInfo reports whether build metadata was set at link time
+func linkedBuildInfo() bool {
+	return GitSHA != "" || BuildTime != "" || Builder != ""
+}
+
+// applyBuildInfo adds the build tags and dist to an event
+func applyBuildInfo(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+	info := currentBuild.Load()
+	if info == nil {
+		info = &buildInfo{gitSHA: GitSHA, buildTime: BuildTime, builder: Builder}
+	}
+
+	tags := map[string]string{
+		"build_sha":  info.gitSHA,
+		"build_time": info.buildTime,
+		"builder":    info.builder,
+	}
+	for key, value := range tags {
+		if value == "" {
+			continue
+		}
+		if event.Tags == nil {
+			event.Tags = make(map[string]string, len(tags))
+		}
+		if _, ok := event.Tags[key]; !ok {
+			event.Tags[key] = value
+		}
+	}
+
+	if event.Dist == "" {
+		event.Dist = info.gitSHA
+	}
+	return event
+}
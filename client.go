@@ -1,21 +1,139 @@
 package sentrykit
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"net/http"
 	"time"
 
 	"github.com/getsentry/sentry-go"
 )
 
+// defaultFlushTimeout bounds flushes that have no caller-provided deadline
+const defaultFlushTimeout = 2 * time.Second
+
 // Config holds Sentry configuration
 type Config struct {
-	DSN              string  // Sentry DSN from your project settings
+	DSN              string  // Sentry DSN from your project settings (or use DSNProvider)
 	Environment      string  // Environment name (development, staging, production)
 	Release          string  // Application release/version (optional)
+	Dist             string  // Distribution of the release, e.g. a build channel (optional)
 	TracesSampleRate float64 // Percentage of transactions to sample (0.0 - 1.0)
-	Debug            bool    // Enable debug mode
+	Debug            bool    // Enable debug mode; also attaches a scope diff to request events
 	AttachStacktrace bool    // Attach stack traces to messages
 	ServerName       string  // Server/host name (optional)
+
+	// Preset fills the options left unset with the defaults of an
+	// environment (PresetDevelopment, PresetStaging or PresetProduction), so
+	// services share one policy; explicit settings win
+	Preset string
+
+	// GlobalTags are set on the root scope, so every event carries them
+	GlobalTags map[string]string
+
+	// Region, Zone, Cluster and ServiceName are topology labels set as the
+	// "region", "zone", "cluster" and "service" tags (optional)
+	Region      string
+	Zone        string
+	Cluster     string
+	ServiceName string
+
+	// TracesSampler decides the sample rate per transaction, e.g. keyed on
+	// the tenant tier extracted by the middleware; overrides TracesSampleRate
+	TracesSampler func(input SamplingInput) float64
+
+	// EnableLogs sends entries written through LoggerFromContext or
+	// sentry.NewLogger to Sentry's Logs product
+	EnableLogs bool
+
+	// BeforeBreadcrumb modifies or drops breadcrumbs before they are recorded (optional)
+	BeforeBreadcrumb BreadcrumbFilter
+
+	// MaxBreadcrumbs is the number of breadcrumbs each scope keeps, oldest
+	// evicted first (default: 30, max: 100)
+	MaxBreadcrumbs int
+
+	// StripInternalFrames removes kit, Fiber and runtime frames from the top of
+	// stack traces so the first visible frame is application code
+	StripInternalFrames bool
+
+	// InternalFramePrefixes overrides the module prefixes stripped by
+	// StripInternalFrames (default: DefaultInternalFramePrefixes)
+	InternalFramePrefixes []string
+
+	// SelfMonitor records the kit's own failures (failed or rate-limited
+	// deliveries, processor panics, dropped events) to InternalLogger and
+	// InternalErrors()
+	SelfMonitor bool
+
+	// InternalLogger receives internal failures and, with Debug, event
+	// problems (default: log.Default())
+	InternalLogger *log.Logger
+
+	// SelfReport also sends internal failures to Sentry as warning events,
+	// at most once per minute per failure kind
+	SelfReport bool
+
+	// LoadShedding drops transactions, then samples errors, under sustained
+	// high capture rates (disabled by default)
+	LoadShedding LoadSheddingConfig
+
+	// PayloadLimits truncates oversized events (contexts, breadcrumbs,
+	// request body) to stay within ingest size limits (disabled by default)
+	PayloadLimits PayloadLimitsConfig
+
+	// ErrorCodes tags events with the application error code of the
+	// captured error and maps codes to fingerprints (disabled by default)
+	ErrorCodes ErrorCodeConfig
+
+	// DevMode explicitly marks a development setup, allowing unsafe
+	// options such as Transport.InsecureSkipVerify
+	DevMode bool
+
+	// Transport tunes event delivery: compression, connection pool,
+	// timeouts and keep-alive (default: sentry-go's transport settings)
+	Transport TransportConfig
+
+	// FlushInterval flushes buffered events periodically, independent of
+	// request flow (0 = only on request completion and shutdown)
+	FlushInterval time.Duration
+
+	// OnCaptured is called with each error or message event accepted for
+	// delivery, after all processors ran, e.g. to count events in the
+	// application's own metrics or to page on specific events. It runs
+	// synchronously on the capturing goroutine, so it must be fast.
+	OnCaptured func(eventID sentry.EventID, event *sentry.Event)
+
+	// OnDropped is called with each error or message event the kit drops,
+	// and why (DropReasonLoadShedding, DropReasonProcessor or
+	// DropReasonQueueFull)
+	OnDropped func(reason string, event *sentry.Event)
+
+	// EventLog keeps a local record (time, route, event ID, level) of every
+	// event sent, e.g. NewFileEventLog for an on-prem audit trail
+	EventLog EventLog
+
+	// SDK overrides the SDK name and version reported to Sentry and the
+	// kit's sdk.integrations entry, e.g. to identify an internal wrapper
+	SDK SDKConfig
+
+	// Clock replaces the system clock for time-dependent behavior (rate
+	// limit and shedding windows, cooldowns, durations, crons) and event
+	// timestamps, e.g. a fake clock in tests. Process-wide, set by Init.
+	Clock Clock
+
+	// IDGenerator replaces sentry-go's random event IDs, e.g. sequential IDs
+	// in tests. Process-wide, set by Init.
+	IDGenerator IDGenerator
+
+	// DSNProvider resolves the DSN (env, file, secret manager) instead of DSN
+	DSNProvider DSNProvider
+
+	// DSNRefreshInterval re-resolves the DSN from DSNProvider periodically and
+	// switches to a new client when it changed (0 = resolve once)
+	DSNRefreshInterval time.Duration
 }
 
 // DefaultConfig returns default configuration
@@ -28,43 +146,261 @@ func DefaultConfig() Config {
 	}
 }
 
+// Validate reports invalid or contradictory options. All problems are
+// returned together so they can be fixed in one pass.
+func (cfg Config) Validate() error {
+	var errs []error
+
+	switch {
+	case cfg.DSN == "" && cfg.DSNProvider == nil:
+		errs = append(errs, errDSNRequired)
+	case cfg.DSN != "" && cfg.DSNProvider != nil:
+		errs = append(errs, errors.New("set either DSN or DSNProvider, not both"))
+	case cfg.DSN != "":
+		if err := validateDSN(cfg.DSN); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := cfg.Transport.validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Transport.InsecureSkipVerify && !cfg.DevMode {
+		errs = append(errs, errors.New("Transport.InsecureSkipVerify is only allowed with DevMode"))
+	}
+	if cfg.FlushInterval < 0 {
+		errs = append(errs, fmt.Errorf("FlushInterval must not be negative, got %s", cfg.FlushInterval))
+	}
+	if _, ok := cfg.GlobalTags[""]; ok {
+		errs = append(errs, errors.New("GlobalTags must not contain an empty key"))
+	}
+	if cfg.DSNRefreshInterval < 0 {
+		errs = append(errs, fmt.Errorf("DSNRefreshInterval must not be negative, got %s", cfg.DSNRefreshInterval))
+	}
+	if cfg.DSNRefreshInterval > 0 && cfg.DSNProvider == nil {
+		errs = append(errs, errors.New("DSNRefreshInterval requires DSNProvider"))
+	}
+
+	if cfg.PayloadLimits.MaxEventBytes < 0 || cfg.PayloadLimits.MaxContextBytes < 0 || cfg.PayloadLimits.MaxBreadcrumbs < 0 || cfg.PayloadLimits.MaxBodyBytes < 0 {
+		errs = append(errs, errors.New("PayloadLimits budgets must not be negative"))
+	}
+
+	if cfg.MaxBreadcrumbs < 0 || cfg.MaxBreadcrumbs > 100 {
+		errs = append(errs, fmt.Errorf("MaxBreadcrumbs must be between 0 and 100, got %d", cfg.MaxBreadcrumbs))
+	}
+
+	if !validPreset(cfg.Preset) {
+		errs = append(errs, fmt.Errorf("unknown Preset %q; use PresetDevelopment, PresetStaging or PresetProduction", cfg.Preset))
+	}
+
+	if cfg.TracesSampleRate < 0 || cfg.TracesSampleRate > 1 {
+		errs = append(errs, fmt.Errorf("TracesSampleRate must be between 0.0 and 1.0, got %v", cfg.TracesSampleRate))
+	}
+
+	if (cfg.ErrorCodes.Extractor != nil || cfg.ErrorCodes.Fingerprints != nil) && !cfg.ErrorCodes.Enabled {
+		errs = append(errs, errors.New("ErrorCodes.Extractor and ErrorCodes.Fingerprints have no effect without ErrorCodes.Enabled"))
+	}
+	if cfg.InternalFramePrefixes != nil && !cfg.StripInternalFrames {
+		errs = append(errs, errors.New("InternalFramePrefixes has no effect without StripInternalFrames"))
+	}
+	if cfg.SelfReport && !cfg.SelfMonitor {
+		errs = append(errs, errors.New("SelfReport requires SelfMonitor"))
+	}
+	if cfg.InternalLogger != nil && !cfg.SelfMonitor && !cfg.Debug {
+		errs = append(errs, errors.New("InternalLogger has no effect without SelfMonitor or Debug"))
+	}
+
+	shedding := cfg.LoadShedding
+	if shedding.TransactionThreshold < 0 || shedding.ErrorThreshold < 0 {
+		errs = append(errs, errors.New("LoadShedding thresholds must not be negative"))
+	}
+	if shedding.TransactionThreshold > 0 && shedding.ErrorThreshold > 0 && shedding.ErrorThreshold < shedding.TransactionThreshold {
+		errs = append(errs, fmt.Errorf("LoadShedding.ErrorThreshold (%d) must not be below TransactionThreshold (%d): transactions are shed before errors", shedding.ErrorThreshold, shedding.TransactionThreshold))
+	}
+	if shedding.ErrorSampleRate < 0 || shedding.ErrorSampleRate > 1 {
+		errs = append(errs, fmt.Errorf("LoadShedding.ErrorSampleRate must be between 0.0 and 1.0, got %v", shedding.ErrorSampleRate))
+	}
+
+	return errors.Join(errs...)
+}
+
 // Init initializes Sentry with the provided configuration
 func Init(cfg Config) error {
-	if cfg.DSN == "" {
-		return fmt.Errorf("sentry DSN is required")
+	cfg = cfg.withPreset()
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid Sentry config: %w", err)
+	}
+
+	cfg, err := resolveDSN(cfg)
+	if err != nil {
+		return err
+	}
+
+	options, shed, err := clientOptions(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize Sentry: %w", err)
+	}
+	client, err := sentry.NewClient(options)
+	if err != nil {
+		return fmt.Errorf("failed to initialize Sentry: %w", err)
+	}
+
+	// Stop goroutines of a previous Init, which closes its client, only
+	// once the new client exists: a failed Init keeps the previous setup
+	stopBackgroundTasks()
+
+	setTimeSource(cfg.Clock, cfg.IDGenerator)
+	setDebugMode(cfg.Debug, cfg.InternalLogger)
+	shedder.Store(shed)
+	sentry.CurrentHub().BindClient(client)
+
+	registerErrorCodes(cfg)
+	registerFrameStripping(cfg)
+	registerPayloadGuard(cfg)
+	sentry.CurrentHub().Scope().SetTags(cfg.rootTags())
+	if linkedBuildInfo() {
+		registerBuildInfo()
+	}
+	recordGlobalScopeBaseline()
+
+	if cfg.DSNRefreshInterval > 0 {
+		addBackgroundTask(startDSNRefresh(cfg, func(client *sentry.Client, shed *loadShedder) {
+			shedder.Store(shed)
+			rebindHub(sentry.CurrentHub(), client)
+		}))
+	}
+	if cfg.FlushInterval > 0 {
+		addBackgroundTask(startPeriodicFlush(sentry.CurrentHub(), cfg.FlushInterval))
+	}
+
+	// Registered last, so the client is closed once nothing rebinds or
+	// flushes it anymore; a later Init or Close stops its transport
+	addBackgroundTask(func() {
+		if client := sentry.CurrentHub().Client(); client != nil {
+			closeClient(client)
+		}
+	})
+	return nil
+}
+
+// rootTags returns the global and topology tags for the root scope
+func (cfg Config) rootTags() map[string]string {
+	tags := make(map[string]string, len(cfg.GlobalTags)+4)
+	for key, value := range cfg.GlobalTags {
+		tags[key] = value
+	}
+
+	topology := map[string]string{
+		"region":  cfg.Region,
+		"zone":    cfg.Zone,
+		"cluster": cfg.Cluster,
+		"service": cfg.ServiceName,
+	}
+	for key, value := range topology {
+		if value != "" {
+			tags[key] = value
+		}
 	}
+	return tags
+}
 
+// clientOptions builds the sentry-go client options for cfg along with the
+// client's load shedder (nil when disabled). Self-monitoring is process-wide
+// and configured here as well.
+func clientOptions(cfg Config) (sentry.ClientOptions, *loadShedder, error) {
 	// Set default environment if not provided
 	if cfg.Environment == "" {
 		cfg.Environment = "development"
 	}
 
-	// Initialize Sentry
-	err := sentry.Init(sentry.ClientOptions{
+	if cfg.SelfMonitor {
+		logger := cfg.InternalLogger
+		if logger == nil {
+			logger = log.Default()
+		}
+		configureSelfMonitor(logger, cfg.SelfReport)
+	}
+
+	shed := newLoadShedder(cfg.LoadShedding)
+
+	var sampler sentry.TracesSampler
+	if cfg.TracesSampler != nil {
+		sampler = tracesSampler(cfg.TracesSampler)
+	}
+
+	// Self-monitoring observes the delivery requests, so it needs the
+	// kit's HTTP client even without transport options
+	var httpClient *http.Client
+	if !cfg.Transport.isZero() || cfg.SelfMonitor {
+		client, err := cfg.Transport.httpClient()
+		if err != nil {
+			return sentry.ClientOptions{}, nil, err
+		}
+		httpClient = client
+	}
+	if cfg.SelfMonitor {
+		httpClient = withSelfMonitor(httpClient)
+	}
+
+	hooks := newCaptureHooks(cfg.OnCaptured, cfg.OnDropped)
+
+	transport := newQueueTransport(cfg.Transport.QueueSize, hooks, cfg.EventLog)
+
+	return sentry.ClientOptions{
 		Dsn:              cfg.DSN,
 		Environment:      cfg.Environment,
 		Release:          cfg.Release,
+		Dist:             cfg.Dist,
+		EnableLogs:       cfg.EnableLogs,
+		EnableTracing:    cfg.TracesSampleRate > 0 || cfg.TracesSampler != nil,
 		TracesSampleRate: cfg.TracesSampleRate,
+		TracesSampler:    sampler,
 		Debug:            cfg.Debug,
 		AttachStacktrace: cfg.AttachStacktrace,
 		ServerName:       cfg.ServerName,
-		BeforeSend: func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
-			// Filter sensitive data if needed
-			// This is a hook where you can modify events before sending
-			return event
-		},
-	})
+		MaxBreadcrumbs:   cfg.MaxBreadcrumbs,
+		Transport:        transport,
+		HTTPClient:       httpClient,
+		Integrations:     withSDKIntegration(cfg.SDK),
+		// Enrichers and scrubbers are registered with RegisterProcessor
+		BeforeSend:            beforeSend(shed, hooks),
+		BeforeSendTransaction: beforeSendTransaction(shed),
+		BeforeBreadcrumb:      beforeBreadcrumb(cfg.BeforeBreadcrumb),
+	}, shed, nil
+}
 
-	if err != nil {
-		return fmt.Errorf("failed to initialize Sentry: %w", err)
+// registerFrameStripping adds the frame stripping processor if enabled.
+// Processors are shared by all clients.
+func registerFrameStripping(cfg Config) {
+	if cfg.StripInternalFrames {
+		prefixes := cfg.InternalFramePrefixes
+		if prefixes == nil {
+			prefixes = DefaultInternalFramePrefixes
+		}
+		RegisterProcessor("sentrykit.strip_frames", stripFramesProcessor(prefixes), 1000)
 	}
+}
 
-	return nil
+// registerPayloadGuard adds the payload size guard if enabled. It runs last,
+// after all other processors.
+func registerPayloadGuard(cfg Config) {
+	if cfg.PayloadLimits.MaxEventBytes > 0 {
+		RegisterProcessor("sentrykit.payload_guard", payloadGuardProcessor(cfg.PayloadLimits), payloadGuardPriority)
+	}
 }
 
 // Close flushes buffered events and closes the Sentry client
 func Close() {
-	sentry.Flush(2 * time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultFlushTimeout)
+	defer cancel()
+	FlushCtx(ctx)
+
+	stopBackgroundTasks()
+}
+
+// FlushCtx waits until buffered events are sent or ctx is done.
+// It returns false if ctx ended before all events were delivered.
+func FlushCtx(ctx context.Context) bool {
+	return sentry.FlushWithContext(ctx)
 }
 
 // CaptureException captures an error and sends it to Sentry
@@ -72,31 +408,39 @@ func CaptureException(err error) *sentry.EventID {
 	return sentry.CaptureException(err)
 }
 
-// CaptureMessage captures a message and sends it to Sentry
+// CaptureMessage captures a message and sends it to Sentry.
+// The level only applies to this message.
 func CaptureMessage(message string, level sentry.Level) *sentry.EventID {
-	event := sentry.CaptureMessage(message)
-	sentry.ConfigureScope(func(scope *sentry.Scope) {
+	return CaptureMessageWithLevel(message, level, nil, nil)
+}
+
+// CaptureMessageWithLevel captures a message with level, tags and extras
+// applied in an isolated scope, so they affect only this event
+func CaptureMessageWithLevel(message string, level sentry.Level, tags map[string]string, extras map[string]interface{}) *sentry.EventID {
+	var eventID *sentry.EventID
+	sentry.WithScope(func(scope *sentry.Scope) {
 		scope.SetLevel(level)
+		scope.SetTags(tags)
+		scope.SetExtras(extras)
+		eventID = sentry.CaptureMessage(message)
 	})
-	return event
+	return eventID
 }
 
 // RecoverWithSentry recovers from panic and sends to Sentry
 func RecoverWithSentry() {
 	if err := recover(); err != nil {
-		sentry.CurrentHub().Recover(err)
-		sentry.Flush(2 * time.Second)
+		recoverValue(context.Background(), sentry.CurrentHub(), err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), defaultFlushTimeout)
+		defer cancel()
+		FlushCtx(ctx)
 	}
 }
 
 // AddBreadcrumb adds a breadcrumb to the current scope
-func AddBreadcrumb(message, category string, data map[string]interface{}) {
-	sentry.AddBreadcrumb(&sentry.Breadcrumb{
-		Message:  message,
-		Category: category,
-		Data:     data,
-		Level:    sentry.LevelInfo,
-	})
+func AddBreadcrumb(message, category string, data map[string]interface{}, options ...BreadcrumbOptions) {
+	sentry.AddBreadcrumb(newBreadcrumb(message, category, data, options))
 }
 
 // SetUser sets the user context in Sentry
@@ -133,3 +477,64 @@ func WithScope(f func(scope *sentry.Scope)) {
 func ConfigureScope(f func(scope *sentry.Scope)) {
 	sentry.ConfigureScope(f)
 }
+
+// MonitorConfig describes the schedule and thresholds of a Crons monitor
+type MonitorConfig = sentry.MonitorConfig
+
+// Monitor schedule units for IntervalMonitor
+const (
+	MonitorUnitMinute = sentry.MonitorScheduleUnitMinute
+	MonitorUnitHour   = sentry.MonitorScheduleUnitHour
+	MonitorUnitDay    = sentry.MonitorScheduleUnitDay
+	MonitorUnitWeek   = sentry.MonitorScheduleUnitWeek
+	MonitorUnitMonth  = sentry.MonitorScheduleUnitMonth
+	MonitorUnitYear   = sentry.MonitorScheduleUnitYear
+)
+
+// CrontabMonitor returns a monitor config using a crontab schedule (e.g. "0 * * * *")
+func CrontabMonitor(schedule string) *MonitorConfig {
+	return &MonitorConfig{
+		Schedule: sentry.CrontabSchedule(schedule),
+	}
+}
+
+// IntervalMonitor returns a monitor config using an interval schedule
+func IntervalMonitor(value int64, unit sentry.MonitorScheduleUnit) *MonitorConfig {
+	return &MonitorConfig{
+		Schedule: sentry.IntervalSchedule(value, unit),
+	}
+}
+
+// StartCheckIn sends an in-progress check-in for the monitor and returns its ID.
+// Pass a monitor config to create or update the monitor (upsert), or nil to use
+// the existing one. The run is recorded for StartMissedRunWatchdog.
+func StartCheckIn(slug string, monitor *MonitorConfig) *sentry.EventID {
+	if monitor != nil {
+		if err := RegisterMonitor(slug, monitor); err != nil {
+			reportInternal("cron", err.Error())
+		}
+	}
+	recordMonitorRun(slug)
+
+	return sentry.CaptureCheckIn(&sentry.CheckIn{
+		MonitorSlug: slug,
+		Status:      sentry.CheckInStatusInProgress,
+	}, monitor)
+}
+
+// FinishCheckIn completes a check-in started with StartCheckIn.
+// The check-in is marked as failed when err is not nil.
+func FinishCheckIn(slug string, checkInID *sentry.EventID, duration time.Duration, err error) *sentry.EventID {
+	checkIn := &sentry.CheckIn{
+		MonitorSlug: slug,
+		Status:      sentry.CheckInStatusOK,
+		Duration:    duration,
+	}
+	if checkInID != nil {
+		checkIn.ID = *checkInID
+	}
+	if err != nil {
+		checkIn.Status = sentry.CheckInStatusError
+	}
+	return sentry.CaptureCheckIn(checkIn, nil)
+}
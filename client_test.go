@@ -0,0 +1,54 @@
+package sentrykit
+
+import (
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+func TestFailedInitKeepsPreviousClient(t *testing.T) {
+	hub := sentry.CurrentHub()
+	previous := hub.Client()
+	t.Cleanup(func() {
+		stopBackgroundTasks()
+		hub.BindClient(previous)
+	})
+
+	if err := Init(Config{DSN: testDSN}); err != nil {
+		t.Fatal(err)
+	}
+	client := hub.Client()
+
+	err := Init(Config{DSN: testDSN, Transport: TransportConfig{TLSRootCA: "/nonexistent/ca.pem"}})
+	if err == nil {
+		t.Fatal("Init succeeded with a missing CA file")
+	}
+
+	if hub.Client() != client {
+		t.Fatal("failed Init replaced the bound client")
+	}
+	// A closed transport never answers a flush
+	if !client.Flush(time.Second) {
+		t.Error("failed Init closed the bound client")
+	}
+}
+
+func TestValidateInternalLogger(t *testing.T) {
+	logger := log.New(io.Discard, "", 0)
+	tests := []struct {
+		cfg     Config
+		wantErr bool
+	}{
+		{Config{DSN: testDSN, InternalLogger: logger}, true},
+		{Config{DSN: testDSN, InternalLogger: logger, SelfMonitor: true}, false},
+		{Config{DSN: testDSN, InternalLogger: logger, Debug: true}, false},
+	}
+	for _, test := range tests {
+		if err := test.cfg.Validate(); (err != nil) != test.wantErr {
+			t.Errorf("Validate(SelfMonitor: %v, Debug: %v) = %v, want error: %v", test.cfg.SelfMonitor, test.cfg.Debug, err, test.wantErr)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package sentrykit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// Client is a named Sentry client with its own DSN, options and scope,
+// independent of the global client configured by Init. Use it to report
+// several logical services hosted in one process to separate projects.
+type Client struct {
+	name string
+	hub  *sentry.Hub
+	shed atomic.Pointer[loadShedder]
+
+	// stops ends the client's background goroutines (DSN refresh, flush)
+	stops []func()
+}
+
+// clients holds the named clients created by NewClient
+var clients sync.Map
+
+// NewClient creates a named client for cfg and registers it for GetClient.
+// Creating a client with an existing name replaces the registered one,
+// which is flushed and closed.
+// Event processors and self-monitoring are shared by all clients.
+func NewClient(name string, cfg Config) (*Client, error) {
+	cfg = cfg.withPreset()
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid Sentry config for client %q: %w", name, err)
+	}
+
+	cfg, err := resolveDSN(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("client %q: %w", name, err)
+	}
+
+	options, shed, err := clientOptions(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Sentry client %q: %w", name, err)
+	}
+	client, err := sentry.NewClient(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Sentry client %q: %w", name, err)
+	}
+	registerErrorCodes(cfg)
+	registerFrameStripping(cfg)
+	registerPayloadGuard(cfg)
+	if linkedBuildInfo() {
+		registerBuildInfo()
+	}
+
+	scope := sentry.NewScope()
+	scope.SetTags(cfg.rootTags())
+
+	c := &Client{
+		name: name,
+		hub:  sentry.NewHub(client, scope),
+	}
+	c.shed.Store(shed)
+
+	if cfg.DSNRefreshInterval > 0 {
+		c.stops = append(c.stops, startDSNRefresh(cfg, func(client *sentry.Client, shed *loadShedder) {
+			c.shed.Store(shed)
+			rebindHub(c.hub, client)
+		}))
+	}
+	if cfg.FlushInterval > 0 {
+		c.stops = append(c.stops, startPeriodicFlush(c.hub, cfg.FlushInterval))
+	}
+
+	if previous, loaded := clients.Swap(name, c); loaded {
+		previous.(*Client).Close()
+	}
+	return c, nil
+}
+
+// GetClient returns the client registered under name, or nil
+func GetClient(name string) *Client {
+	if c, ok := clients.Load(name); ok {
+		return c.(*Client)
+	}
+	return nil
+}
+
+// Name returns the name the client was registered under
+func (c *Client) Name() string {
+	return c.name
+}
+
+// Hub returns the client's root hub. Its scope applies to every event sent
+// through the client, like the global scope does for Init.
+func (c *Client) Hub() *sentry.Hub {
+	return c.hub
+}
+
+// CaptureException captures an error through this client
+func (c *Client) CaptureException(err error) *sentry.EventID {
+	return c.hub.CaptureException(err)
+}
+
+// CaptureMessage captures a message through this client.
+// The level only applies to this message.
+func (c *Client) CaptureMessage(message string, level sentry.Level) *sentry.EventID {
+	var eventID *sentry.EventID
+	c.hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetLevel(level)
+		eventID = c.hub.CaptureMessage(message)
+	})
+	return eventID
+}
+
+// SheddingLevel returns how much telemetry the client is currently shedding
+func (c *Client) SheddingLevel() SheddingLevel {
+	return c.shed.Load().currentLevel()
+}
+
+// FlushCtx waits until the client's buffered events are sent or ctx is done
+func (c *Client) FlushCtx(ctx context.Context) bool {
+	return c.hub.FlushWithContext(ctx)
+}
+
+// Close flushes the client's buffered events and unregisters it
+func (c *Client) Close() {
+	for _, stop := range c.stops {
+		stop()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultFlushTimeout)
+	defer cancel()
+	c.FlushCtx(ctx)
+	c.hub.Client().Close()
+	clients.CompareAndDelete(c.name, c)
+}
@@ -0,0 +1,56 @@
+package sentrykit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+)
+
+func TestNewClientClosesReplacedClient(t *testing.T) {
+	first, err := NewClient("replaced", Config{DSN: testDSN})
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := &closeRecorder{}
+	first.hub.BindClient(mustClient(t, transport))
+
+	second, err := NewClient("replaced", Config{DSN: testDSN})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+
+	if !transport.flushed || !transport.closed {
+		t.Errorf("replaced client flushed=%v closed=%v, want both", transport.flushed, transport.closed)
+	}
+	if GetClient("replaced") != second {
+		t.Error("closing the replaced client unregistered its replacement")
+	}
+}
+
+// closeRecorder records whether it was flushed and closed
+type closeRecorder struct {
+	sentry.MockTransport
+	flushed bool
+	closed  bool
+}
+
+func (t *closeRecorder) FlushWithContext(context.Context) bool {
+	t.flushed = true
+	return true
+}
+
+func (t *closeRecorder) Close() {
+	t.closed = true
+}
+
+// mustClient returns a client sending through transport
+func mustClient(t *testing.T, transport sentry.Transport) *sentry.Client {
+	t.Helper()
+	client, err := sentry.NewClient(sentry.ClientOptions{Dsn: testDSN, Transport: transport})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
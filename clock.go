@@ -0,0 +1,87 @@
+package sentrykit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// Clock tells the current time. Replace it through Config.Clock to make
+// time-dependent behavior deterministic in tests: rate limit and load
+// shedding windows, cooldowns, request, handler and dependency durations,
+// cron missed-run detection and event timestamps.
+type Clock interface {
+	Now() time.Time
+}
+
+// IDGenerator creates event IDs, replacing sentry-go's random UUIDs
+// through Config.IDGenerator, e.g. for predictable IDs in tests
+type IDGenerator interface {
+	NewEventID() sentry.EventID
+}
+
+// systemClock is the real clock
+type systemClock struct{}
+
+// Now returns time.Now()
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// timeSource holds the process-wide clock and ID generator set by Init
+var timeSource = struct {
+	mu    sync.RWMutex
+	clock Clock
+	ids   IDGenerator
+}{clock: systemClock{}}
+
+// setTimeSource installs the clock and ID generator; nil restores the
+// defaults
+func setTimeSource(clock Clock, ids IDGenerator) {
+	if clock == nil {
+		clock = systemClock{}
+	}
+	timeSource.mu.Lock()
+	defer timeSource.mu.Unlock()
+	timeSource.clock = clock
+	timeSource.ids = ids
+}
+
+// now returns the current time of the configured clock
+func now() time.Time {
+	timeSource.mu.RLock()
+	defer timeSource.mu.RUnlock()
+	return timeSource.clock.Now()
+}
+
+// since returns the time elapsed since t on the configured clock
+func since(t time.Time) time.Duration {
+	return now().Sub(t)
+}
+
+// applyTimeSource stamps an event with the configured clock and ID
+// generator, when they replace the defaults
+func applyTimeSource(event *sentry.Event) {
+	timeSource.mu.RLock()
+	clock := timeSource.clock
+	timeSource.mu.RUnlock()
+
+	if _, real := clock.(systemClock); !real {
+		event.Timestamp = clock.Now()
+	}
+	applyEventID(event)
+}
+
+// applyEventID replaces the event ID when an ID generator is configured.
+// Transactions only get their ID replaced: their timestamps are the real
+// span times.
+func applyEventID(event *sentry.Event) {
+	timeSource.mu.RLock()
+	ids := timeSource.ids
+	timeSource.mu.RUnlock()
+
+	if ids != nil {
+		event.EventID = ids.NewEventID()
+	}
+}
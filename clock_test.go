@@ -0,0 +1,52 @@
+package sentrykit
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+)
+
+// sequentialIDs numbers events from 1
+type sequentialIDs struct{ n int }
+
+func (g *sequentialIDs) NewEventID() sentry.EventID {
+	g.n++
+	return sentry.EventID(fmt.Sprintf("%032x", g.n))
+}
+
+func TestIDGeneratorAppliesToTransactions(t *testing.T) {
+	transport := &sentry.MockTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:                   testDSN,
+		Transport:             transport,
+		EnableTracing:         true,
+		TracesSampleRate:      1,
+		BeforeSendTransaction: beforeSendTransaction(nil),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	setTimeSource(nil, &sequentialIDs{})
+	t.Cleanup(func() { setTimeSource(nil, nil) })
+
+	app := fiber.New()
+	app.Use(New(MiddlewareConfig{Client: &Client{hub: sentry.NewHub(client, sentry.NewScope())}}))
+	app.Get("/", func(c fiber.Ctx) error { return c.SendString("ok") })
+	if _, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	transaction := waitForEvent(transport, time.Second, func(event *sentry.Event) bool {
+		return event.Type == "transaction"
+	})
+	if transaction == nil {
+		t.Fatal("transaction not sent")
+	}
+	if want := sentry.EventID(fmt.Sprintf("%032x", 1)); transaction.EventID != want {
+		t.Errorf("transaction ID = %s, want %s", transaction.EventID, want)
+	}
+}
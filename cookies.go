@@ -0,0 +1,57 @@
+package sentrykit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CookieMapping records a cookie in the request's "cookies" context
+type CookieMapping struct {
+	// Name of the cookie
+	Name string
+
+	// Key in the context (default: Name)
+	Key string
+
+	// Hash records a short SHA-256 digest instead of the value, enough to
+	// correlate events without storing the value
+	Hash bool
+
+	// MaxLength truncates the recorded value (or digest) to this many bytes
+	// (0 = unlimited; required when Hash is not set)
+	MaxLength int
+}
+
+// key returns the context key of the cookie
+func (m CookieMapping) key() string {
+	if m.Key != "" {
+		return m.Key
+	}
+	return m.Name
+}
+
+// apply hashes and truncates a cookie value as configured
+func (m CookieMapping) apply(value string) string {
+	if m.Hash {
+		value = hashValue(value)
+	}
+	if m.MaxLength > 0 && len(value) > m.MaxLength {
+		value = value[:m.MaxLength]
+	}
+	// Framework cookie values may point into reused request buffers
+	return strings.Clone(value)
+}
+
+// validate reports mappings that would store raw or unbounded values
+func (m CookieMapping) validate() error {
+	switch {
+	case m.Name == "":
+		return errors.New("cookie mapping needs a Name")
+	case m.MaxLength < 0:
+		return fmt.Errorf("cookie %q: MaxLength must not be negative, got %d", m.Name, m.MaxLength)
+	case !m.Hash && m.MaxLength == 0:
+		return fmt.Errorf("cookie %q: set Hash or MaxLength so the raw value isn't stored", m.Name)
+	}
+	return nil
+}
@@ -0,0 +1,601 @@
+package sentrykit
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3/middleware/healthcheck"
+)
+
+// requestInfo is the framework-agnostic description of an incoming request
+type requestInfo struct {
+	URL       string
+	Method    string
+	Path      string
+	Query     string
+	Headers   map[string]string
+	IP        string
+	UserAgent string
+
+	// Incoming trace propagation headers
+	SentryTrace string
+	Baggage     string
+
+	// Header looks up any request header, including ones not captured
+	Header func(name string) string
+
+	// Cookie looks up a request cookie value
+	Cookie func(name string) string
+
+	// BaseURL is the scheme and host the request was sent to
+	BaseURL string
+
+	// Body returns the request body, or is nil when the framework doesn't
+	// buffer it (net/http)
+	Body func() []byte
+
+	// Response describes the response written so far
+	Response func() responseInfo
+}
+
+// requestState tracks the Sentry hub and transaction of a single request.
+// It holds the capture/enrichment logic shared by all framework adapters.
+type requestState struct {
+	cfg         MiddlewareConfig
+	req         requestInfo
+	transaction *sentry.Span
+	start       time.Time
+
+	// firstByte is when the adapter saw the first response byte written;
+	// zero when the framework buffers the response (Fiber, fasthttp)
+	firstByte time.Time
+
+	// enrich adds adapter-specific data (user, tenant, ...) to a new hub
+	enrich func(hub *sentry.Hub)
+
+	// hub is the per-request hub; in shared-hub mode it stays nil until
+	// something needs it
+	hubMu sync.Mutex
+	hub   *sentry.Hub
+
+	// carrier is the plain clone of the base hub that shared-hub requests
+	// start with; requestHub enriches it in place when first needed, so the
+	// global hub is never handed out as the request hub
+	carrier *sentry.Hub
+
+	// params are the scrubbed route params, known once a route matched
+	params map[string]string
+
+	// errorCaptured is set when the request error was already reported, by
+	// another integration's panic capture or a helper such as Retry, so it
+	// isn't captured twice
+	errorCaptured bool
+
+	// events are the IDs recorded by requestHooksProcessor, forgotten when
+	// the request ends
+	eventsMu sync.Mutex
+	events   []sentry.EventID
+
+	// forced is set when the request carries the debug header, forcing
+	// sampling and capture of client errors
+	forced bool
+
+	// tracked is set while the request is listed for CPU spike reports
+	tracked bool
+
+	// groupByRoute prepends the route template to the fingerprint of the
+	// captured error
+	groupByRoute bool
+
+	// status is the response status, known once the handler returned
+	status int
+
+	// handler names the function that handled the request, when the
+	// framework exposes it (Fiber)
+	handler string
+
+	// timeout and deadline describe the request deadline, when known,
+	// for timeout events and the deadline budget of captured events;
+	// written under deadlineMu, as events may be captured concurrently
+	deadlineMu sync.Mutex
+	timeout    time.Duration
+	deadline   time.Time
+}
+
+// startRequest prepares the per-request hub and starts the request
+// transaction, continuing an incoming trace if present. In shared-hub mode
+// the request starts on a plain clone of the base hub, which requestHub
+// enriches lazily.
+func startRequest(ctx context.Context, cfg MiddlewareConfig, req requestInfo, enrich func(hub *sentry.Hub)) *requestState {
+	if cfg.AuditScopeIsolation && debugEnabled() {
+		auditGlobalScope(req.Path)
+	}
+
+	r := &requestState{
+		cfg:    cfg,
+		req:    req,
+		enrich: enrich,
+		start:  now(),
+		forced: debugForced(cfg, req),
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		r.deadline = deadline
+	}
+	trackRequest(r)
+
+	var hub *sentry.Hub
+	if cfg.SharedHub {
+		r.carrier = r.baseHub().Clone()
+		hub = r.carrier
+	} else {
+		hub = r.requestHub()
+	}
+
+	op := cfg.TransactionOp
+	if op == "" {
+		op = "http.server"
+	}
+
+	options := []sentry.SpanOption{
+		sentry.WithOpName(op),
+		sentry.WithTransactionSource(sentry.SourceURL),
+		sentry.ContinueFromHeaders(req.SentryTrace, req.Baggage),
+	}
+	if r.forced {
+		options = append(options, sentry.WithSpanSampled(sentry.SampledTrue))
+	}
+
+	r.transaction = sentry.StartTransaction(
+		sentry.SetHubOnContext(ctx, hub),
+		fmt.Sprintf("%s %s", req.Method, req.Path),
+		options...,
+	)
+
+	if cfg.MaxVersionSkew > 0 {
+		r.reportVersionSkew()
+	}
+
+	return r
+}
+
+// requestHub returns the per-request hub, cloning and enriching it on first use
+func (r *requestState) requestHub() *sentry.Hub {
+	r.hubMu.Lock()
+	defer r.hubMu.Unlock()
+
+	if r.hub != nil {
+		return r.hub
+	}
+
+	// Create a new hub for this request, or enrich the one the shared-hub
+	// request started with
+	root := r.baseHub()
+	hub := r.carrier
+	if hub == nil {
+		hub = root.Clone()
+	} else {
+		root = hub
+	}
+
+	var rootScope scopeSnapshot
+	if debugEnabled() {
+		rootScope = snapshotScope(root.Scope())
+	}
+
+	// The base scope may hold a span of its own; point the request scope at
+	// this request's transaction
+	if r.transaction != nil {
+		hub.Scope().SetSpan(r.transaction)
+	}
+
+	// Add request context
+	hub.Scope().SetContext("request", r.requestContext())
+
+	// Add custom tags
+	hub.Scope().SetTag("path", r.req.Path)
+	hub.Scope().SetTag("method", r.req.Method)
+	if r.forced {
+		hub.Scope().SetTag("debug_forced", "true")
+	}
+
+	if team := r.owner(); team != "" {
+		tag := r.cfg.OwnershipTag
+		if tag == "" {
+			tag = "team"
+		}
+		hub.Scope().SetTag(tag, team)
+	}
+
+	// Map configured headers to tags; mapping a header is an explicit
+	// opt-in, so it doesn't depend on the header capture mode
+	for header, tag := range r.cfg.HeaderTags {
+		if value := r.req.Header(header); value != "" {
+			hub.Scope().SetTag(tag, truncateTag(value))
+		}
+	}
+
+	r.setReleaseTags(hub)
+
+	if cookies := r.cookieContext(); len(cookies) > 0 {
+		hub.Scope().SetContext("cookies", cookies)
+	}
+
+	if hooks := newCaptureHooks(r.cfg.OnCaptured, r.cfg.OnDropped); hooks != nil {
+		hub.Scope().AddEventProcessor(requestHooksProcessor(r, hooks))
+	}
+
+	if r.cfg.ReplayBundle || r.cfg.CurlCommand || r.cfg.HARAttachment {
+		hub.Scope().AddEventProcessor(replayProcessor(r))
+	}
+
+	if r.cfg.MaxBreadcrumbs > 0 {
+		hub.Scope().AddEventProcessor(breadcrumbBudget(r.cfg.MaxBreadcrumbs))
+	}
+
+	hub.Scope().AddEventProcessor(deadlineProcessor(r))
+
+	if r.enrich != nil {
+		r.enrich(hub)
+	}
+
+	if debugEnabled() {
+		hub.Scope().AddEventProcessor(scopeDiffProcessor(rootScope, snapshotScope(hub.Scope())))
+	}
+
+	r.hub = hub
+	return hub
+}
+
+// requestContext returns the "request" context data; callers hold hubMu
+func (r *requestState) requestContext() map[string]interface{} {
+	data := map[string]interface{}{
+		"url":          r.req.URL,
+		"method":       r.req.Method,
+		"query_string": r.req.Query,
+		"headers":      r.req.Headers,
+		"ip":           r.req.IP,
+		"user_agent":   r.req.UserAgent,
+	}
+	if len(r.params) > 0 {
+		data["params"] = r.params
+	}
+	return data
+}
+
+// owner returns the team owning the request path by the longest matching
+// Ownership prefix. Prefixes match whole path segments.
+func (r *requestState) owner() string {
+	var team string
+	longest := -1
+	for prefix, owner := range r.cfg.Ownership {
+		if len(prefix) > longest && matchesPrefix(r.req.Path, prefix) {
+			team, longest = owner, len(prefix)
+		}
+	}
+	return team
+}
+
+// matchesPrefix reports whether path is prefix or lies below it
+func matchesPrefix(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	return len(path) == len(prefix) || path[len(prefix)] == '/'
+}
+
+// cookieContext returns the mapped cookies, hashed or truncated as configured
+func (r *requestState) cookieContext() map[string]interface{} {
+	if len(r.cfg.Cookies) == 0 || r.req.Cookie == nil {
+		return nil
+	}
+
+	data := make(map[string]interface{}, len(r.cfg.Cookies))
+	for _, mapping := range r.cfg.Cookies {
+		if value := r.req.Cookie(mapping.Name); value != "" {
+			data[mapping.key()] = mapping.apply(value)
+		}
+	}
+	return data
+}
+
+// setRouteParams adds the matched route params to the request context
+func (r *requestState) setRouteParams(params map[string]string) {
+	r.hubMu.Lock()
+	defer r.hubMu.Unlock()
+
+	r.params = params
+	if r.hub != nil {
+		r.hub.Scope().SetContext("request", r.requestContext())
+	}
+}
+
+// baseHub returns the hub of the configured client, or the current hub
+func (r *requestState) baseHub() *sentry.Hub {
+	if r.cfg.Client != nil {
+		return r.cfg.Client.Hub()
+	}
+	return sentry.CurrentHub()
+}
+
+// createdHub returns the per-request hub if it was created, or nil
+func (r *requestState) createdHub() *sentry.Hub {
+	r.hubMu.Lock()
+	defer r.hubMu.Unlock()
+	return r.hub
+}
+
+// context returns the request context carrying the hub and transaction
+func (r *requestState) context() context.Context {
+	return r.transaction.Context()
+}
+
+// end finishes the transaction and records the response time.
+// Adapters defer it right after startRequest.
+func (r *requestState) end() {
+	r.transaction.Finish()
+	recordRequestDuration(since(r.start))
+	untrackRequest(r)
+	r.forgetEvents()
+}
+
+// trackEvent remembers an event recorded for the request's hooks
+func (r *requestState) trackEvent(id sentry.EventID) {
+	r.eventsMu.Lock()
+	r.events = append(r.events, id)
+	r.eventsMu.Unlock()
+}
+
+// forgetEvents drops the hooks of events that never reached BeforeSend
+func (r *requestState) forgetEvents() {
+	r.eventsMu.Lock()
+	events := r.events
+	r.events = nil
+	r.eventsMu.Unlock()
+	for _, id := range events {
+		requestEvents.Delete(id)
+	}
+}
+
+// recoverPanic reports a recovered panic, flushes if configured and
+// re-panics when Repanic is set. Otherwise it returns the ID of the
+// captured event, if any.
+func (r *requestState) recoverPanic(ctx context.Context, err interface{}) *sentry.EventID {
+	r.transaction.Status = sentry.SpanStatusInternalError
+	r.status = http.StatusInternalServerError
+	hub := r.requestHub()
+	eventID := recoverValue(ctx, hub, err)
+
+	if r.cfg.WaitForDelivery {
+		flushHub(ctx, hub, r.cfg.Timeout)
+	}
+
+	if r.cfg.Repanic {
+		panic(err)
+	}
+	return eventID
+}
+
+// finish names the transaction after the matched route, records the response
+// status, captures server errors and flushes if configured. It returns the ID
+// of the captured event, if any. An empty route keeps the URL-based
+// transaction name.
+func (r *requestState) finish(ctx context.Context, route string, code int, err error) *sentry.EventID {
+	// Name the transaction after the matched route and record the outcome
+	if route != "" {
+		r.transaction.Name = r.transactionName(route)
+		r.transaction.Source = sentry.SourceRoute
+	}
+	r.transaction.Status = sentry.HTTPtoSpanStatus(code)
+	r.status = code
+
+	timedOut := err != nil && isTimeoutError(err)
+	if timedOut {
+		r.transaction.Status = sentry.SpanStatusDeadlineExceeded
+	}
+
+	// Record response timings
+	timings := r.timings()
+	for key, value := range timings {
+		r.transaction.SetData(key, value)
+	}
+
+	// Capture errors (5xx and timeouts only, 4xx too for forced requests)
+	minStatus := 500
+	if r.forced {
+		minStatus = 400
+	}
+
+	var eventID *sentry.EventID
+	if err != nil && (code >= minStatus || timedOut) && !r.errorCaptured {
+		hub := r.requestHub()
+		hub.Scope().SetExtras(timings)
+		if timedOut {
+			r.setTimeoutContext(hub)
+		} else if r.groupByRoute && route != "" {
+			hub.Scope().SetFingerprint([]string{route, "{{ default }}"})
+		}
+		hub.Scope().SetContext("error_details", r.errorDetails(err, code))
+		if r.req.Body != nil && isBodyParseError(err) {
+			hub.Scope().SetContext("body_parse", bodyDiagnostics(r.req.Header, r.req.Body()))
+		}
+		eventID = hub.CaptureException(err)
+	}
+
+	// Flush events if configured; a hub that was never created has nothing to flush
+	if hub := r.createdHub(); r.cfg.WaitForDelivery && hub != nil {
+		flushHub(ctx, hub, r.cfg.Timeout)
+	}
+
+	return eventID
+}
+
+// errorDetails returns the "error_details" context of a captured error
+func (r *requestState) errorDetails(err error, code int) map[string]interface{} {
+	details := map[string]interface{}{
+		"error":       err.Error(),
+		"path":        r.req.Path,
+		"method":      r.req.Method,
+		"status":      code,
+		"status_text": http.StatusText(code),
+		"ip":          r.req.IP,
+		"user_agent":  r.req.UserAgent,
+	}
+	if r.handler != "" {
+		details["handler"] = r.handler
+	}
+	return details
+}
+
+// setTimeoutContext classifies the event as a timeout, grouped per
+// transaction, with the deadline and elapsed time
+func (r *requestState) setTimeoutContext(hub *sentry.Hub) {
+	data := map[string]interface{}{
+		"elapsed_ms": float64(since(r.start)) / float64(time.Millisecond),
+	}
+	timeout, deadline := r.requestDeadline()
+	if timeout > 0 {
+		data["timeout_ms"] = float64(timeout) / float64(time.Millisecond)
+	}
+	if !deadline.IsZero() {
+		data["deadline"] = deadline.UTC().Format(time.RFC3339Nano)
+	}
+
+	hub.Scope().SetTag("error_category", "timeout")
+	hub.Scope().SetContext("timeout", data)
+	hub.Scope().SetFingerprint([]string{"timeout", r.transaction.Name})
+}
+
+// timings returns the handler duration and time to first byte in
+// milliseconds. For buffered responses the first byte can't be observed, so
+// the time until the response was ready is used.
+func (r *requestState) timings() map[string]interface{} {
+	handlerDuration := since(r.start)
+	timeToFirstByte := handlerDuration
+	if !r.firstByte.IsZero() {
+		timeToFirstByte = r.firstByte.Sub(r.start)
+	}
+
+	return map[string]interface{}{
+		"handler_duration_ms":   float64(handlerDuration) / float64(time.Millisecond),
+		"time_to_first_byte_ms": float64(timeToFirstByte) / float64(time.Millisecond),
+	}
+}
+
+// transactionName names the request transaction after the matched route
+func (r *requestState) transactionName(route string) string {
+	if r.cfg.TransactionNameFormatter != nil {
+		return r.cfg.TransactionNameFormatter(r.req.Method, route)
+	}
+	return fmt.Sprintf("%s %s", r.req.Method, route)
+}
+
+// flushHub flushes the hub, bounded by both the parent context and timeout
+func flushHub(parent context.Context, hub *sentry.Hub, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+	return hub.FlushWithContext(ctx)
+}
+
+// maxTagValueLength is the longest tag value Sentry accepts
+const maxTagValueLength = 200
+
+// truncateTag shortens a value to the maximum tag value length
+func truncateTag(value string) string {
+	if len(value) > maxTagValueLength {
+		return value[:maxTagValueLength]
+	}
+	return value
+}
+
+// headerFilter returns whether a request header may be captured under cfg.
+// With an allowlist only listed headers are captured; sensitive headers are
+// never captured.
+func headerFilter(cfg MiddlewareConfig) func(key string) bool {
+	// The debug header carries a secret token
+	isDebugHeader := func(key string) bool {
+		return cfg.DebugHeader != "" && strings.EqualFold(key, cfg.DebugHeader)
+	}
+
+	if len(cfg.HeaderAllowlist) == 0 {
+		return func(key string) bool {
+			return !isSensitiveHeader(key) && !isDebugHeader(key)
+		}
+	}
+
+	allowed := make(map[string]struct{}, len(cfg.HeaderAllowlist))
+	for _, header := range cfg.HeaderAllowlist {
+		allowed[strings.ToLower(header)] = struct{}{}
+	}
+	return func(key string) bool {
+		_, ok := allowed[strings.ToLower(key)]
+		return ok && !isSensitiveHeader(key) && !isDebugHeader(key)
+	}
+}
+
+// debugForced reports whether the request carries the configured debug
+// header with the right token
+func debugForced(cfg MiddlewareConfig, req requestInfo) bool {
+	if cfg.DebugHeader == "" || cfg.DebugToken == "" {
+		return false
+	}
+	value := req.Header(cfg.DebugHeader)
+	return subtle.ConstantTimeCompare([]byte(value), []byte(cfg.DebugToken)) == 1
+}
+
+// HeaderFilter returns whether a request header (or gRPC metadata key) may
+// be captured under the config, for integrations outside this package
+func (cfg MiddlewareConfig) HeaderFilter() func(key string) bool {
+	return headerFilter(cfg)
+}
+
+// DefaultHealthcheckPaths are the liveness and readiness endpoints of
+// Fiber's healthcheck middleware
+var DefaultHealthcheckPaths = []string{healthcheck.DefaultLivenessEndpoint, healthcheck.DefaultReadinessEndpoint}
+
+// healthcheckFilter returns whether a request path is a healthcheck that
+// the adapters should pass through uninstrumented
+func healthcheckFilter(cfg MiddlewareConfig) func(path string) bool {
+	if cfg.TraceHealthchecks {
+		return func(string) bool { return false }
+	}
+
+	paths := cfg.HealthcheckPaths
+	if paths == nil {
+		paths = DefaultHealthcheckPaths
+	}
+	skip := make(map[string]struct{}, len(paths))
+	for _, path := range paths {
+		skip[path] = struct{}{}
+	}
+	return func(path string) bool {
+		_, ok := skip[path]
+		return ok
+	}
+}
+
+// DefaultParamDenylist lists route param name fragments whose values are
+// scrubbed from the request context (matched case-insensitively)
+var DefaultParamDenylist = []string{"token", "secret", "password", "email", "key", "signature"}
+
+// scrubParams returns the params with values of denylisted names filtered
+func scrubParams(params map[string]string, denylist []string) map[string]string {
+	for name := range params {
+		if isDenylisted(name, denylist) {
+			params[name] = filteredValue
+		}
+	}
+	return params
+}
+
+// isSensitiveHeader reports whether a header must never be sent to Sentry.
+// Matching is case-insensitive since gRPC metadata keys are lowercase.
+func isSensitiveHeader(key string) bool {
+	return strings.EqualFold(key, "Authorization") || strings.EqualFold(key, "Cookie") || strings.EqualFold(key, "X-Api-Key")
+}
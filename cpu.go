@@ -0,0 +1,216 @@
+package sentrykit
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// CPUMonitorConfig configures CPU spike reporting
+type CPUMonitorConfig struct {
+	// Threshold is the fraction of available CPU (GOMAXPROCS) counted as a
+	// spike (default: 0.8)
+	Threshold float64
+
+	// Interval between CPU usage samples (default: 5s)
+	Interval time.Duration
+
+	// Sustain is the number of consecutive samples above Threshold before a
+	// spike is reported (default: 3)
+	Sustain int
+
+	// ProfileDuration is the length of the attached CPU profile
+	// (default: 5s; negative = no profile)
+	ProfileDuration time.Duration
+
+	// Cooldown is the minimum time between two reports (default: 10m)
+	Cooldown time.Duration
+}
+
+// activeRequests tracks in-flight requests while a CPU monitor runs
+var activeRequests struct {
+	monitors atomic.Int32
+	requests sync.Map // *requestState -> "METHOD /path"
+}
+
+// trackRequest records an in-flight request if a CPU monitor runs
+func trackRequest(r *requestState) {
+	if activeRequests.monitors.Load() > 0 {
+		activeRequests.requests.Store(r, r.req.Method+" "+r.req.Path)
+		r.tracked = true
+	}
+}
+
+// untrackRequest removes a finished request from the in-flight requests
+func untrackRequest(r *requestState) {
+	if r.tracked {
+		activeRequests.requests.Delete(r)
+	}
+}
+
+// StartCPUMonitor samples the process's CPU usage and, when it stays above
+// Threshold for Sustain samples, captures a warning with a "cpu" context
+// listing the in-flight requests and a short CPU profile ("cpu.pprof")
+// attached, correlating resource burns to endpoints. Call the returned
+// function to stop.
+func StartCPUMonitor(config ...CPUMonitorConfig) (stop func()) {
+	var cfg CPUMonitorConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Threshold <= 0 || cfg.Threshold > 1 {
+		cfg.Threshold = 0.8
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Second
+	}
+	if cfg.Sustain <= 0 {
+		cfg.Sustain = 3
+	}
+	if cfg.ProfileDuration == 0 {
+		cfg.ProfileDuration = 5 * time.Second
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 10 * time.Minute
+	}
+
+	activeRequests.monitors.Add(1)
+
+	limiter := &cooldown{interval: cfg.Cooldown}
+	sampler := newCPUSampler()
+	above := 0
+
+	stopTicker := startTicker(cfg.Interval, func() {
+		usage := sampler.usage()
+		if usage < cfg.Threshold {
+			above = 0
+			return
+		}
+		if above++; above < cfg.Sustain || !limiter.allow() {
+			return
+		}
+
+		active := activeRequestSummary(10)
+
+		var attachments []*sentry.Attachment
+		if cfg.ProfileDuration > 0 {
+			if profile := cpuProfile(cfg.ProfileDuration); profile != nil {
+				attachments = append(attachments, profile)
+			}
+		}
+
+		captureDiagnostic(sentry.CurrentHub(), diagnostic{
+			message:     fmt.Sprintf("CPU usage at %.0f%% for %s", usage*100, time.Duration(above)*cfg.Interval),
+			fingerprint: []string{"cpu", "spike"},
+			context: map[string]interface{}{
+				"usage":           usage,
+				"threshold":       cfg.Threshold,
+				"sustained_for_s": (time.Duration(above) * cfg.Interval).Seconds(),
+				"gomaxprocs":      runtime.GOMAXPROCS(0),
+				"goroutines":      runtime.NumGoroutine(),
+				"active_requests": active,
+			},
+			attachments: attachments,
+		})
+		above = 0
+
+		// Don't count the profiling period as the next sample
+		sampler.usage()
+	})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			stopTicker()
+			activeRequests.monitors.Add(-1)
+		})
+	}
+}
+
+// cpuSampler computes the process's CPU usage between samples from its CPU
+// time (user + system), so it sees busy loops that don't allocate. Both
+// clocks are real: CPU time can't follow an injected Clock.
+type cpuSampler struct {
+	lastCPU  time.Duration
+	lastWall time.Time
+}
+
+// newCPUSampler returns a sampler starting from the current totals
+func newCPUSampler() *cpuSampler {
+	s := &cpuSampler{}
+	s.usage()
+	return s
+}
+
+// usage returns the fraction of available CPU (GOMAXPROCS) used since the
+// last call; 0 where process CPU time can't be read
+func (s *cpuSampler) usage() float64 {
+	cpu, ok := processCPUTime()
+	wall := time.Now()
+	if !ok {
+		return 0
+	}
+
+	deltaCPU := cpu - s.lastCPU
+	deltaWall := wall.Sub(s.lastWall)
+	s.lastCPU, s.lastWall = cpu, wall
+
+	if deltaWall <= 0 || deltaCPU <= 0 {
+		return 0
+	}
+	return float64(deltaCPU) / (float64(deltaWall) * float64(runtime.GOMAXPROCS(0)))
+}
+
+// activeRequestSummary returns the most common in-flight requests as
+// "count METHOD /path" strings, most first
+func activeRequestSummary(limit int) []string {
+	counts := make(map[string]int)
+	activeRequests.requests.Range(func(_, value interface{}) bool {
+		counts[value.(string)]++
+		return true
+	})
+
+	requests := make([]string, 0, len(counts))
+	for request := range counts {
+		requests = append(requests, request)
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		if counts[requests[i]] != counts[requests[j]] {
+			return counts[requests[i]] > counts[requests[j]]
+		}
+		return requests[i] < requests[j]
+	})
+	if len(requests) > limit {
+		requests = requests[:limit]
+	}
+
+	result := make([]string, len(requests))
+	for i, request := range requests {
+		result[i] = fmt.Sprintf("%d %s", counts[request], request)
+	}
+	return result
+}
+
+// cpuProfile records a CPU profile for d as an attachment, or returns nil
+// when another CPU profile is already running
+func cpuProfile(d time.Duration) *sentry.Attachment {
+	var buf bytes.Buffer
+	if err := pprof.StartCPUProfile(&buf); err != nil {
+		return nil
+	}
+	time.Sleep(d)
+	pprof.StopCPUProfile()
+
+	return &sentry.Attachment{
+		Filename:    "cpu.pprof",
+		ContentType: "application/octet-stream",
+		Payload:     buf.Bytes(),
+	}
+}
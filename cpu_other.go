@@ -0,0 +1,11 @@
+//go:build !unix
+
+package sentrykit
+
+import "time"
+
+// processCPUTime is not available on this platform, so StartCPUMonitor
+// never reports a spike
+func processCPUTime() (time.Duration, bool) {
+	return 0, false
+}
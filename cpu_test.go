@@ -0,0 +1,57 @@
+package sentrykit
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// burn spins without allocating until stop is set, so no GC runs
+func burn(stop *atomic.Bool) uint64 {
+	var x uint64 = 1
+	for !stop.Load() {
+		for i := 0; i < 1000; i++ {
+			x ^= x<<13 + 1
+		}
+	}
+	return x
+}
+
+func TestCPUMonitorDetectsNonAllocatingBusyLoop(t *testing.T) {
+	transport := bindTestClient(t)
+
+	stopMonitor := StartCPUMonitor(CPUMonitorConfig{
+		Threshold:       0.5,
+		Interval:        100 * time.Millisecond,
+		Sustain:         2,
+		ProfileDuration: -1,
+	})
+	defer stopMonitor()
+
+	var stop atomic.Bool
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.GOMAXPROCS(0); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			burn(&stop)
+		}()
+	}
+
+	event := waitForEvent(transport, 5*time.Second, func(event *sentry.Event) bool {
+		return len(event.Fingerprint) == 2 && event.Fingerprint[0] == "cpu"
+	})
+	stop.Store(true)
+	wg.Wait()
+
+	if event == nil {
+		t.Fatal("no CPU spike captured while all Ps were busy")
+	}
+	if usage, _ := event.Contexts["cpu"]["usage"].(float64); usage < 0.5 {
+		t.Errorf("reported usage = %v, want at least the 0.5 threshold", usage)
+	}
+}
@@ -0,0 +1,342 @@
+package sentrykit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// cronSchedule computes the next expected run of a monitor after a time
+type cronSchedule interface {
+	next(after time.Time) time.Time
+}
+
+// registeredMonitor is a monitor known to the missed-run watchdog
+type registeredMonitor struct {
+	slug     string
+	schedule cronSchedule
+	grace    time.Duration
+
+	// since is the start of the current expected slot: the last run or,
+	// before the first run, the registration time
+	since time.Time
+}
+
+// cronMonitors holds the monitors registered through the Crons helpers
+var cronMonitors struct {
+	mu       sync.Mutex
+	monitors map[string]*registeredMonitor
+}
+
+// RegisterMonitor makes a monitor known to StartMissedRunWatchdog before its
+// first run, so a job that never starts is detected too. StartCheckIn
+// registers monitors passed to it automatically.
+func RegisterMonitor(slug string, monitor *MonitorConfig) error {
+	schedule, err := parseMonitorSchedule(monitor)
+	if err != nil {
+		return fmt.Errorf("monitor %s: %w", slug, err)
+	}
+
+	grace := time.Minute
+	if monitor.CheckInMargin > 0 {
+		grace = time.Duration(monitor.CheckInMargin) * time.Minute
+	}
+
+	cronMonitors.mu.Lock()
+	defer cronMonitors.mu.Unlock()
+	if cronMonitors.monitors == nil {
+		cronMonitors.monitors = make(map[string]*registeredMonitor)
+	}
+	since := now()
+	if existing, ok := cronMonitors.monitors[slug]; ok {
+		since = existing.since
+	}
+	cronMonitors.monitors[slug] = &registeredMonitor{
+		slug:     slug,
+		schedule: schedule,
+		grace:    grace,
+		since:    since,
+	}
+	return nil
+}
+
+// recordMonitorRun marks a registered monitor as started now
+func recordMonitorRun(slug string) {
+	cronMonitors.mu.Lock()
+	defer cronMonitors.mu.Unlock()
+	if monitor, ok := cronMonitors.monitors[slug]; ok {
+		monitor.since = now()
+	}
+}
+
+// StartMissedRunWatchdog checks registered monitors every interval (default:
+// 30s) and captures a warning as soon as a job has not started within its
+// CheckInMargin (default: 1 minute) of the scheduled time. It complements
+// Sentry's server-side missed check-in detection with an immediate, local
+// signal carrying the process's context. Call the returned function to stop.
+func StartMissedRunWatchdog(interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return startTicker(interval, checkMissedRuns)
+}
+
+// checkMissedRuns reports monitors whose expected run is overdue. Each
+// missed slot is reported once.
+func checkMissedRuns() {
+	type missedRun struct {
+		slug     string
+		expected time.Time
+	}
+
+	current := now()
+	var missed []missedRun
+
+	cronMonitors.mu.Lock()
+	for _, monitor := range cronMonitors.monitors {
+		expected := monitor.schedule.next(monitor.since)
+		if expected.IsZero() || current.Before(expected.Add(monitor.grace)) {
+			continue
+		}
+		missed = append(missed, missedRun{slug: monitor.slug, expected: expected})
+		monitor.since = expected
+	}
+	cronMonitors.mu.Unlock()
+
+	for _, run := range missed {
+		expected := run.expected.UTC().Format(time.RFC3339)
+		captureDiagnostic(sentry.CurrentHub(), diagnostic{
+			message:     fmt.Sprintf("Cron job %s did not start at %s", run.slug, expected),
+			fingerprint: []string{"cron", run.slug},
+			context: map[string]interface{}{
+				"monitor_slug": run.slug,
+				"expected":     expected,
+				"overdue_ms":   milliseconds(current.Sub(run.expected)),
+			},
+		})
+	}
+}
+
+// parseMonitorSchedule reads the crontab or interval schedule of a monitor
+func parseMonitorSchedule(monitor *MonitorConfig) (cronSchedule, error) {
+	if monitor == nil || monitor.Schedule == nil {
+		return nil, fmt.Errorf("no schedule")
+	}
+
+	// sentry-go's schedule types are unexported; read their JSON form
+	raw, err := json.Marshal(monitor.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	var schedule struct {
+		Type  string          `json:"type"`
+		Value json.RawMessage `json:"value"`
+		Unit  string          `json:"unit"`
+	}
+	if err := json.Unmarshal(raw, &schedule); err != nil {
+		return nil, err
+	}
+
+	location := time.UTC
+	if monitor.Timezone != "" {
+		if location, err = time.LoadLocation(monitor.Timezone); err != nil {
+			return nil, err
+		}
+	}
+
+	switch schedule.Type {
+	case "crontab":
+		var expr string
+		if err := json.Unmarshal(schedule.Value, &expr); err != nil {
+			return nil, err
+		}
+		return parseCrontab(expr, location)
+	case "interval":
+		var value int64
+		if err := json.Unmarshal(schedule.Value, &value); err != nil {
+			return nil, err
+		}
+		if value <= 0 {
+			return nil, fmt.Errorf("interval must be positive, got %d", value)
+		}
+		return intervalSchedule{value: int(value), unit: schedule.Unit}, nil
+	}
+	return nil, fmt.Errorf("unsupported schedule type %q", schedule.Type)
+}
+
+// intervalSchedule runs every value units after the previous run
+type intervalSchedule struct {
+	value int
+	unit  string
+}
+
+// next returns after plus the interval
+func (s intervalSchedule) next(after time.Time) time.Time {
+	switch sentry.MonitorScheduleUnit(s.unit) {
+	case sentry.MonitorScheduleUnitMinute:
+		return after.Add(time.Duration(s.value) * time.Minute)
+	case sentry.MonitorScheduleUnitHour:
+		return after.Add(time.Duration(s.value) * time.Hour)
+	case sentry.MonitorScheduleUnitDay:
+		return after.AddDate(0, 0, s.value)
+	case sentry.MonitorScheduleUnitWeek:
+		return after.AddDate(0, 0, 7*s.value)
+	case sentry.MonitorScheduleUnitMonth:
+		return after.AddDate(0, s.value, 0)
+	case sentry.MonitorScheduleUnitYear:
+		return after.AddDate(s.value, 0, 0)
+	}
+	return time.Time{}
+}
+
+// crontabSchedule is a parsed five-field crontab expression
+type crontabSchedule struct {
+	minute, hour, dom, month, dow uint64 // bit sets of allowed values
+	domAny, dowAny                bool
+	location                      *time.Location
+}
+
+// crontabMacros are the supported @ shorthands
+var crontabMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// parseCrontab parses "minute hour day-of-month month day-of-week" with
+// *, lists, ranges and steps
+func parseCrontab(expr string, location *time.Location) (cronSchedule, error) {
+	if macro, ok := crontabMacros[strings.TrimSpace(expr)]; ok {
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("crontab %q must have 5 fields", expr)
+	}
+
+	bounds := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+	var sets [5]uint64
+	for i, field := range fields {
+		set, err := parseCrontabField(field, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("crontab %q: %w", expr, err)
+		}
+		sets[i] = set
+	}
+
+	// Sunday is 0 or 7
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+
+	// A day field covering its whole range, like * or */1, counts as
+	// unrestricted for the day rule
+	const allDays, allWeekdays = (1<<32 - 1) &^ 1, 1<<7 - 1
+
+	return crontabSchedule{
+		minute:   sets[0],
+		hour:     sets[1],
+		dom:      sets[2],
+		month:    sets[3],
+		dow:      sets[4],
+		domAny:   sets[2]&allDays == allDays,
+		dowAny:   sets[4]&allWeekdays == allWeekdays,
+		location: location,
+	}, nil
+}
+
+// parseCrontabField returns the bit set of values matched by a field
+func parseCrontabField(field string, min, max int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			part = part[:i]
+		}
+
+		low, high := min, max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err1, err2 error
+			low, err1 = strconv.Atoi(bounds[0])
+			high, err2 = strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("invalid range %q", part)
+			}
+		default:
+			value, err := strconv.Atoi(part)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			low, high = value, value
+			if step > 1 {
+				high = max
+			}
+		}
+		if low < min || high > max || low > high {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, min, max)
+		}
+
+		for value := low; value <= high; value += step {
+			set |= 1 << uint(value)
+		}
+	}
+	return set, nil
+}
+
+// next returns the first scheduled minute after the given time, or the
+// zero time if none follows within five years
+func (s crontabSchedule) next(after time.Time) time.Time {
+	t := after.In(s.location).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			// Step in the schedule's zone; truncating absolute time misses
+			// the hour in zones with a half-hour offset
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.location)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches applies cron's day rule: when both day fields are restricted,
+// either may match
+func (s crontabSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dowMatch
+	case s.dowAny:
+		return domMatch
+	default:
+		return domMatch || dowMatch
+	}
+}
@@ -0,0 +1,46 @@
+package sentrykit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCrontabNext(t *testing.T) {
+	kolkata := time.FixedZone("IST", 5*3600+30*60)
+	tests := []struct {
+		expr     string
+		location *time.Location
+		after    time.Time
+		want     time.Time
+	}{
+		{"0 11 * * *", time.UTC, time.Date(2026, 3, 2, 10, 59, 0, 0, time.UTC), time.Date(2026, 3, 2, 11, 0, 0, 0, time.UTC)},
+		{"0 11 * * *", time.UTC, time.Date(2026, 3, 2, 11, 0, 0, 0, time.UTC), time.Date(2026, 3, 3, 11, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.UTC, time.Date(2026, 3, 2, 10, 7, 30, 0, time.UTC), time.Date(2026, 3, 2, 10, 15, 0, 0, time.UTC)},
+		{"@monthly", time.UTC, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 11 * * *", kolkata, time.Date(2026, 3, 2, 8, 0, 0, 0, kolkata), time.Date(2026, 3, 2, 11, 0, 0, 0, kolkata)},
+		{"30 0 * * *", kolkata, time.Date(2026, 3, 2, 22, 10, 0, 0, kolkata), time.Date(2026, 3, 3, 0, 30, 0, 0, kolkata)},
+		// 2026-03-02 is a Monday; with a day-of-week of */1 the
+		// day-of-month alone decides
+		{"0 0 15 * */1", time.UTC, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 */1 * 5", time.UTC, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)},
+		// Both restricted: either matches
+		{"0 0 15 * 5", time.UTC, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		schedule, err := parseCrontab(test.expr, test.location)
+		if err != nil {
+			t.Fatalf("parseCrontab(%q): %v", test.expr, err)
+		}
+		if got := schedule.next(test.after); !got.Equal(test.want) {
+			t.Errorf("%q in %s after %s = %s, want %s", test.expr, test.location, test.after, got, test.want)
+		}
+	}
+}
+
+func TestParseCrontabErrors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := parseCrontab(expr, time.UTC); err == nil {
+			t.Errorf("parseCrontab(%q) succeeded, want an error", expr)
+		}
+	}
+}
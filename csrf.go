@@ -0,0 +1,88 @@
+package sentrykit
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/csrf"
+)
+
+// CSRFConfig configures how CSRF failures are reported
+type CSRFConfig struct {
+	// CaptureEvents sends an event per failure, grouped by reason.
+	// When false, failures are only recorded as breadcrumbs.
+	CaptureEvents bool
+
+	// Level of captured events (default: warning)
+	Level sentry.Level
+}
+
+// CSRFErrorHandler returns an ErrorHandler for Fiber's csrf middleware that
+// reports failures as a distinct "csrf" category and responds with 403.
+// Register the csrf middleware after the Sentry middleware.
+func CSRFErrorHandler(config ...CSRFConfig) fiber.ErrorHandler {
+	cfg := CSRFConfig{Level: sentry.LevelWarning}
+	if len(config) > 0 {
+		cfg = config[0]
+		if cfg.Level == "" {
+			cfg.Level = sentry.LevelWarning
+		}
+	}
+
+	return func(c fiber.Ctx, err error) error {
+		hub := GetHubFromContext(c)
+		reason := csrfReason(err)
+		data := map[string]interface{}{
+			"reason":  reason,
+			"error":   err.Error(),
+			"referer": strings.Clone(c.Get(fiber.HeaderReferer)),
+			"origin":  strings.Clone(c.Get(fiber.HeaderOrigin)),
+			"method":  strings.Clone(c.Method()),
+			"path":    strings.Clone(c.Path()),
+		}
+
+		hub.AddBreadcrumb(&sentry.Breadcrumb{
+			Message:  "CSRF check failed: " + reason,
+			Category: "csrf",
+			Data:     data,
+			Level:    sentry.LevelWarning,
+		}, nil)
+
+		if cfg.CaptureEvents {
+			hub.WithScope(func(scope *sentry.Scope) {
+				scope.SetLevel(cfg.Level)
+				scope.SetTag("error_category", "csrf")
+				scope.SetTag("csrf_reason", reason)
+				scope.SetContext("csrf", data)
+				scope.SetFingerprint([]string{"csrf", reason})
+				hub.CaptureMessage("CSRF check failed: " + reason)
+			})
+		}
+
+		return fiber.ErrForbidden
+	}
+}
+
+// csrfReason maps csrf middleware errors to a stable reason key
+func csrfReason(err error) string {
+	switch {
+	case errors.Is(err, csrf.ErrTokenNotFound):
+		return "token_not_found"
+	case errors.Is(err, csrf.ErrTokenInvalid):
+		return "token_invalid"
+	case errors.Is(err, csrf.ErrRefererNotFound):
+		return "referer_not_found"
+	case errors.Is(err, csrf.ErrRefererInvalid):
+		return "referer_invalid"
+	case errors.Is(err, csrf.ErrRefererNoMatch):
+		return "referer_no_match"
+	case errors.Is(err, csrf.ErrOriginInvalid):
+		return "origin_invalid"
+	case errors.Is(err, csrf.ErrOriginNoMatch):
+		return "origin_no_match"
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,45 @@
+package sentrykit
+
+import (
+	"log"
+	"sync"
+)
+
+// debugMode holds the debug settings of Init: with Config.Debug the kit adds
+// troubleshooting aids (scope diffs, event checks) on top of sentry-go's
+// debug output
+var debugMode struct {
+	mu      sync.RWMutex
+	enabled bool
+	logger  *log.Logger
+}
+
+// setDebugMode enables or disables debug aids, logging to logger (nil =
+// log.Default())
+func setDebugMode(enabled bool, logger *log.Logger) {
+	if logger == nil {
+		logger = log.Default()
+	}
+	debugMode.mu.Lock()
+	defer debugMode.mu.Unlock()
+	debugMode.enabled = enabled
+	debugMode.logger = logger
+}
+
+// debugEnabled reports whether Init was called with Config.Debug
+func debugEnabled() bool {
+	debugMode.mu.RLock()
+	defer debugMode.mu.RUnlock()
+	return debugMode.enabled
+}
+
+// debugf logs a debug message when debug mode is enabled
+func debugf(format string, args ...interface{}) {
+	debugMode.mu.RLock()
+	enabled, logger := debugMode.enabled, debugMode.logger
+	debugMode.mu.RUnlock()
+
+	if enabled {
+		logger.Printf("sentrykit: "+format, args...)
+	}
+}
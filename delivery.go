@@ -0,0 +1,261 @@
+package sentrykit
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// deliveryRateWindow is the window events per second are measured over
+const deliveryRateWindow = 10 * time.Second
+
+// DeliveryStatus is a snapshot of event delivery across all clients
+type DeliveryStatus struct {
+	// QueueDepth is the number of events waiting in the transport queues
+	// to be sent
+	QueueDepth int
+
+	// InFlight is the number of envelopes currently being sent
+	InFlight int
+
+	// EventsPerSecond is the rate of events handed to the transport over
+	// the last complete 10 second window, including dropped ones
+	EventsPerSecond float64
+
+	// Sent is the number of envelopes sent since start, including ones
+	// Sentry rejected (see InternalErrors)
+	Sent uint64
+
+	// Dropped counts the events dropped since start per reason
+	// (DropReasonLoadShedding, DropReasonProcessor, DropReasonQueueFull)
+	Dropped map[string]uint64
+
+	// Shedding is the load shedding level of the client configured by Init
+	Shedding SheddingLevel
+}
+
+// delivery tracks event delivery for DeliveryStats
+var delivery struct {
+	queued   atomic.Int64
+	inFlight atomic.Int64
+	sent     atomic.Uint64
+
+	mu      sync.Mutex
+	dropped map[string]uint64
+	rate    rateMeter
+}
+
+// DeliveryStats reports whether telemetry delivery is keeping up: a growing
+// queue or queue_full drops mean events are produced faster than they are
+// sent, e.g. to scale out. Transport failures
+// and rate limiting by Sentry are reported by InternalErrors.
+func DeliveryStats() DeliveryStatus {
+	delivery.mu.Lock()
+	dropped := make(map[string]uint64, len(delivery.dropped))
+	for reason, count := range delivery.dropped {
+		dropped[reason] = count
+	}
+	rate := delivery.rate.rate()
+	delivery.mu.Unlock()
+
+	return DeliveryStatus{
+		QueueDepth:      int(delivery.queued.Load()),
+		InFlight:        int(delivery.inFlight.Load()),
+		EventsPerSecond: rate,
+		Sent:            delivery.sent.Load(),
+		Dropped:         dropped,
+		Shedding:        CurrentSheddingLevel(),
+	}
+}
+
+// recordOffered counts an event handed to the transport
+func recordOffered() {
+	delivery.mu.Lock()
+	defer delivery.mu.Unlock()
+	delivery.rate.observe()
+}
+
+// recordDrop counts an event dropped for reason
+func recordDrop(reason string) {
+	delivery.mu.Lock()
+	defer delivery.mu.Unlock()
+	if delivery.dropped == nil {
+		delivery.dropped = make(map[string]uint64)
+	}
+	delivery.dropped[reason]++
+}
+
+// rateMeter counts occurrences in fixed windows, like the load shedder
+type rateMeter struct {
+	windowStart time.Time
+	count       int
+	previous    int
+}
+
+// observe counts an occurrence
+func (m *rateMeter) observe() {
+	m.roll()
+	m.count++
+}
+
+// rate returns the occurrences per second of the last complete window
+func (m *rateMeter) rate() float64 {
+	m.roll()
+	return float64(m.previous) / deliveryRateWindow.Seconds()
+}
+
+// roll starts a new window once the current one is complete
+func (m *rateMeter) roll() {
+	current := now()
+	elapsed := current.Sub(m.windowStart)
+	if elapsed < deliveryRateWindow {
+		return
+	}
+
+	// An idle gap longer than a window means nothing was counted in the
+	// last complete one
+	m.previous = m.count
+	if elapsed >= 2*deliveryRateWindow {
+		m.previous = 0
+	}
+	m.windowStart = current
+	m.count = 0
+}
+
+// defaultQueueSize is the number of events waiting to be sent before
+// further ones are dropped, like sentry-go's default BufferSize
+const defaultQueueSize = 30
+
+// queueTransport sends events from a bounded queue on one worker goroutine
+// through sentry-go's synchronous HTTP transport, like sentry-go's own
+// asynchronous transport, but with the queue observable by DeliveryStats
+// and overflows reported as queue_full. Sent events are recorded in the
+// event log (optional).
+type queueTransport struct {
+	inner sentry.Transport
+	hooks *captureHooks
+	log   EventLog
+
+	queue   chan *sentry.Event
+	flushes chan chan struct{}
+	done    chan struct{}
+	start   sync.Once
+	stop    sync.Once
+}
+
+// newQueueTransport returns a transport queueing up to size events
+// (default: defaultQueueSize)
+func newQueueTransport(size int, hooks *captureHooks, log EventLog) *queueTransport {
+	if size <= 0 {
+		size = defaultQueueSize
+	}
+	return &queueTransport{
+		inner:   sentry.NewHTTPSyncTransport(),
+		hooks:   hooks,
+		log:     log,
+		queue:   make(chan *sentry.Event, size),
+		flushes: make(chan chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Configure configures the inner transport and starts the worker
+func (t *queueTransport) Configure(options sentry.ClientOptions) {
+	t.inner.Configure(options)
+	t.start.Do(func() { go t.worker() })
+}
+
+// SendEvent queues the event, dropping it when the queue is full
+func (t *queueTransport) SendEvent(event *sentry.Event) {
+	recordOffered()
+
+	delivery.queued.Add(1)
+	select {
+	case t.queue <- event:
+		return
+	default:
+	}
+	delivery.queued.Add(-1)
+
+	reportInternal("queue_full", "transport queue full, event dropped")
+	recordDrop(DropReasonQueueFull)
+	if event.Type != "transaction" {
+		t.hooks.dropped(DropReasonQueueFull, event)
+	}
+}
+
+// Flush sends queued events, waiting up to timeout
+func (t *queueTransport) Flush(timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return t.FlushWithContext(ctx)
+}
+
+// FlushWithContext sends queued events, waiting until ctx is done
+func (t *queueTransport) FlushWithContext(ctx context.Context) bool {
+	sent := make(chan struct{})
+	select {
+	case t.flushes <- sent:
+	case <-t.done:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+
+	select {
+	case <-sent:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Close sends what is queued and stops the worker
+func (t *queueTransport) Close() {
+	t.Flush(defaultFlushTimeout)
+	t.stop.Do(func() { close(t.done) })
+	t.inner.Close()
+}
+
+// worker sends queued events in order, draining the queue on flush requests
+func (t *queueTransport) worker() {
+	for {
+		select {
+		case <-t.done:
+			return
+		case event := <-t.queue:
+			t.send(event)
+		case sent := <-t.flushes:
+			t.drain()
+			close(sent)
+		}
+	}
+}
+
+// drain sends the events queued so far
+func (t *queueTransport) drain() {
+	for {
+		select {
+		case event := <-t.queue:
+			t.send(event)
+		default:
+			return
+		}
+	}
+}
+
+// send delivers one event through the inner transport
+func (t *queueTransport) send(event *sentry.Event) {
+	delivery.queued.Add(-1)
+	delivery.inFlight.Add(1)
+	t.inner.SendEvent(event)
+	delivery.inFlight.Add(-1)
+	delivery.sent.Add(1)
+
+	if t.log != nil {
+		appendEventLog(t.log, event)
+	}
+}
@@ -0,0 +1,74 @@
+package sentrykit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+func TestDeliveryStatsTrackTransportQueue(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+
+	options, _, err := clientOptions(Config{
+		DSN:       "http://key@" + strings.TrimPrefix(server.URL, "http://") + "/1",
+		Transport: TransportConfig{QueueSize: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := sentry.NewClient(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	hub := sentry.NewHub(client, sentry.NewScope())
+
+	before := DeliveryStats()
+	beforeErrors := InternalErrors()
+
+	// The first event blocks the worker in the request, the second waits in
+	// the queue and the third overflows it
+	hub.CaptureMessage("in flight")
+	deadline := time.Now().Add(time.Second)
+	for DeliveryStats().InFlight == before.InFlight && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	hub.CaptureMessage("queued")
+	hub.CaptureMessage("overflow")
+
+	during := DeliveryStats()
+	if during.InFlight != before.InFlight+1 {
+		t.Errorf("InFlight = %d, want %d", during.InFlight, before.InFlight+1)
+	}
+	if during.QueueDepth != before.QueueDepth+1 {
+		t.Errorf("QueueDepth = %d, want %d", during.QueueDepth, before.QueueDepth+1)
+	}
+	if during.Dropped[DropReasonQueueFull] != before.Dropped[DropReasonQueueFull]+1 {
+		t.Errorf("queue_full drops = %d, want %d", during.Dropped[DropReasonQueueFull], before.Dropped[DropReasonQueueFull]+1)
+	}
+
+	if got := InternalErrors()["queue_full"]; got != beforeErrors["queue_full"]+1 {
+		t.Errorf("queue_full internal errors = %d, want %d", got, beforeErrors["queue_full"]+1)
+	}
+
+	close(release)
+	if !hub.Flush(time.Second) {
+		t.Fatal("queue not flushed")
+	}
+
+	after := DeliveryStats()
+	if after.InFlight != before.InFlight || after.QueueDepth != before.QueueDepth {
+		t.Errorf("after flush InFlight = %d, QueueDepth = %d, want %d, %d", after.InFlight, after.QueueDepth, before.InFlight, before.QueueDepth)
+	}
+	if after.Sent != before.Sent+2 {
+		t.Errorf("Sent = %d, want %d", after.Sent, before.Sent+2)
+	}
+}
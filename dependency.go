@@ -0,0 +1,73 @@
+package sentrykit
+
+import (
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+)
+
+// DependencyConfig configures DependencyCall
+type DependencyConfig struct {
+	// Span also records the call as a child span of the request
+	// transaction, with kind as the operation and name as the description
+	Span bool
+}
+
+// DependencyCall runs fn, a call to an outbound dependency (SMTP, S3, a
+// payment gateway, ...), and records a breadcrumb with its outcome and
+// duration on the request hub. It returns fn's error unchanged.
+//
+//	err := sentrykit.DependencyCall(c, "stripe", "payment", func() error {
+//	    return charge(order)
+//	}, sentrykit.DependencyConfig{Span: true})
+func DependencyCall(c fiber.Ctx, name, kind string, fn func() error, config ...DependencyConfig) error {
+	var cfg DependencyConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	var span *sentry.Span
+	if cfg.Span {
+		parent := c.UserContext()
+		span = sentry.StartSpan(parent, kind, sentry.WithDescription(name))
+		defer span.Finish()
+
+		// Nest spans started inside fn under this one
+		c.SetUserContext(span.Context())
+		defer c.SetUserContext(parent)
+	}
+
+	start := now()
+	err := fn()
+	duration := since(start)
+
+	if span != nil {
+		span.Status = spanStatusFromError(err)
+	}
+
+	data := map[string]interface{}{
+		"dependency":  name,
+		"kind":        kind,
+		"duration_ms": float64(duration) / float64(time.Millisecond),
+		"outcome":     "success",
+	}
+	message := name + " call succeeded"
+	level := sentry.LevelInfo
+	if err != nil {
+		data["outcome"] = "error"
+		data["error"] = err.Error()
+		message = name + " call failed"
+		level = sentry.LevelError
+	}
+
+	GetHubFromContext(c).AddBreadcrumb(&sentry.Breadcrumb{
+		Type:     "default",
+		Category: "dependency." + kind,
+		Message:  message,
+		Data:     data,
+		Level:    level,
+	}, nil)
+
+	return err
+}
@@ -0,0 +1,238 @@
+package sentrykit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// dsnResolveTimeout bounds a single DSN lookup
+const dsnResolveTimeout = 10 * time.Second
+
+// DSNProvider resolves the Sentry DSN at startup (and on refresh), so it
+// doesn't have to live in plain configuration
+type DSNProvider interface {
+	DSN(ctx context.Context) (string, error)
+}
+
+// DSNProviderFunc adapts a function to a DSNProvider
+type DSNProviderFunc func(ctx context.Context) (string, error)
+
+// DSN calls f
+func (f DSNProviderFunc) DSN(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// EnvDSN reads the DSN from an environment variable
+func EnvDSN(name string) DSNProvider {
+	return DSNProviderFunc(func(ctx context.Context) (string, error) {
+		dsn := os.Getenv(name)
+		if dsn == "" {
+			return "", fmt.Errorf("environment variable %s is empty", name)
+		}
+		return dsn, nil
+	})
+}
+
+// FileDSN reads the DSN from a file, e.g. a mounted Kubernetes secret
+func FileDSN(path string) DSNProvider {
+	return DSNProviderFunc(func(ctx context.Context) (string, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("reading DSN file: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	})
+}
+
+// SecretGetter fetches a secret string by ID, e.g. a wrapper around the AWS
+// SDK's secretsmanager GetSecretValue
+type SecretGetter func(ctx context.Context, secretID string) (string, error)
+
+// AWSSecretsManagerDSN reads the DSN from an AWS Secrets Manager secret
+// fetched with getSecret. If field is set, the secret is a JSON object and
+// the DSN is read from that key; otherwise the whole secret is the DSN.
+// The kit doesn't depend on the AWS SDK, so the caller supplies the client:
+//
+//	sm := secretsmanager.NewFromConfig(awsCfg)
+//	provider := sentrykit.AWSSecretsManagerDSN(func(ctx context.Context, id string) (string, error) {
+//	    out, err := sm.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: &id})
+//	    if err != nil {
+//	        return "", err
+//	    }
+//	    return aws.ToString(out.SecretString), nil
+//	}, "prod/sentry", "dsn")
+func AWSSecretsManagerDSN(getSecret SecretGetter, secretID, field string) DSNProvider {
+	return DSNProviderFunc(func(ctx context.Context) (string, error) {
+		secret, err := getSecret(ctx, secretID)
+		if err != nil {
+			return "", fmt.Errorf("fetching secret %s: %w", secretID, err)
+		}
+		if field == "" {
+			return strings.TrimSpace(secret), nil
+		}
+
+		var values map[string]interface{}
+		if err := json.Unmarshal([]byte(secret), &values); err != nil {
+			return "", fmt.Errorf("secret %s is not a JSON object: %w", secretID, err)
+		}
+		return stringField(values, field, "secret "+secretID)
+	})
+}
+
+// VaultConfig configures reading the DSN from a HashiCorp Vault KV v2 secret
+type VaultConfig struct {
+	// Address of the Vault server (default: $VAULT_ADDR)
+	Address string
+
+	// Token used to authenticate (default: $VAULT_TOKEN)
+	Token string
+
+	// Path of the secret including the KV v2 data segment, e.g. "secret/data/sentry"
+	Path string
+
+	// Field holding the DSN (default: "dsn")
+	Field string
+
+	// HTTPClient used for requests (default: http.DefaultClient)
+	HTTPClient *http.Client
+}
+
+// VaultDSN reads the DSN from a Vault KV v2 secret over Vault's HTTP API
+func VaultDSN(cfg VaultConfig) DSNProvider {
+	if cfg.Address == "" {
+		cfg.Address = os.Getenv("VAULT_ADDR")
+	}
+	if cfg.Token == "" {
+		cfg.Token = os.Getenv("VAULT_TOKEN")
+	}
+	if cfg.Field == "" {
+		cfg.Field = "dsn"
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+
+	return DSNProviderFunc(func(ctx context.Context) (string, error) {
+		url := strings.TrimSuffix(cfg.Address, "/") + "/v1/" + strings.TrimPrefix(cfg.Path, "/")
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return "", fmt.Errorf("building Vault request: %w", err)
+		}
+		req.Header.Set("X-Vault-Token", cfg.Token)
+
+		resp, err := cfg.HTTPClient.Do(req)
+		if err != nil {
+			return "", fmt.Errorf("reading Vault secret %s: %w", cfg.Path, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("reading Vault secret %s: unexpected status %d", cfg.Path, resp.StatusCode)
+		}
+
+		var body struct {
+			Data struct {
+				Data map[string]interface{} `json:"data"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			return "", fmt.Errorf("decoding Vault secret %s: %w", cfg.Path, err)
+		}
+		return stringField(body.Data.Data, cfg.Field, "Vault secret "+cfg.Path)
+	})
+}
+
+// stringField returns a non-empty string value from a decoded secret
+func stringField(values map[string]interface{}, field, source string) (string, error) {
+	value, ok := values[field].(string)
+	if !ok || value == "" {
+		return "", fmt.Errorf("%s has no %q field", source, field)
+	}
+	return value, nil
+}
+
+// resolveDSN returns cfg with the DSN filled in from its provider, if any
+func resolveDSN(cfg Config) (Config, error) {
+	if cfg.DSNProvider == nil {
+		return cfg, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dsnResolveTimeout)
+	defer cancel()
+
+	dsn, err := cfg.DSNProvider.DSN(ctx)
+	if err != nil {
+		return cfg, fmt.Errorf("resolving Sentry DSN: %w", err)
+	}
+	if err := validateDSN(dsn); err != nil {
+		return cfg, fmt.Errorf("resolving Sentry DSN: %w", err)
+	}
+
+	cfg.DSN = dsn
+	return cfg, nil
+}
+
+// validateDSN reports a malformed DSN
+func validateDSN(dsn string) error {
+	if _, err := sentry.NewDsn(dsn); err != nil {
+		return fmt.Errorf("invalid DSN (expected https://<key>@<host>/<project>): %w", err)
+	}
+	return nil
+}
+
+// startDSNRefresh periodically re-resolves the DSN and calls rebind with a
+// new client when it changed. Failed lookups keep the current client and
+// are reported as internal "dsn" failures. Call the returned function to stop.
+func startDSNRefresh(cfg Config, rebind func(client *sentry.Client, shed *loadShedder)) (stop func()) {
+	current := cfg.DSN
+	return startTicker(cfg.DSNRefreshInterval, func() {
+		resolved, err := resolveDSN(cfg)
+		if err != nil {
+			reportInternal("dsn", err.Error())
+			return
+		}
+		if resolved.DSN == current {
+			return
+		}
+
+		options, shed, err := clientOptions(resolved)
+		if err != nil {
+			reportInternal("dsn", err.Error())
+			return
+		}
+		client, err := sentry.NewClient(options)
+		if err != nil {
+			reportInternal("dsn", err.Error())
+			return
+		}
+		rebind(client, shed)
+		current = resolved.DSN
+	})
+}
+
+// rebindHub binds a new client to hub, then flushes and closes the old one
+func rebindHub(hub *sentry.Hub, client *sentry.Client) {
+	previous := hub.Client()
+	hub.BindClient(client)
+	if previous != nil {
+		closeClient(previous)
+	}
+}
+
+// closeClient sends what client has buffered and stops its transport.
+// sentry-go's transport drops buffered events on Close, so it flushes first.
+func closeClient(client *sentry.Client) {
+	client.Flush(defaultFlushTimeout)
+	client.Close()
+}
+
+// errDSNRequired is returned when neither a DSN nor a provider is configured
+var errDSNRequired = errors.New("sentry DSN is required (set DSN or DSNProvider)")
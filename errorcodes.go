@@ -0,0 +1,86 @@
+package sentrykit
+
+import (
+	"errors"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// errorCodePriority runs the error code processor early, so application
+// processors with higher priorities see the tag and fingerprint
+const errorCodePriority = 100
+
+// ErrorCodeConfig tags events with the application error code of the
+// captured error, aligning Sentry issues with an internal error taxonomy
+type ErrorCodeConfig struct {
+	// Enabled tags error events with "error_code"
+	Enabled bool
+
+	// Extractor returns the code of an error, or "" when it has none
+	// (default: the Code() string method of the first error in the chain
+	// implementing it)
+	Extractor func(err error) string
+
+	// Fingerprints maps error codes to grouping fingerprints, replacing the
+	// default grouping of events with that code. Use "{{ default }}" to keep
+	// splitting a code by stack trace.
+	Fingerprints map[string][]string
+}
+
+// codedError is an error carrying an application error code
+type codedError interface {
+	error
+	Code() string
+}
+
+// ErrorCode returns the code of the first error in err's chain implementing
+// Code() string, or ""
+func ErrorCode(err error) string {
+	var coded codedError
+	if errors.As(err, &coded) {
+		return coded.Code()
+	}
+	return ""
+}
+
+// errorCodeProcessor tags events with the code of the captured error and
+// applies the configured fingerprint
+func errorCodeProcessor(cfg ErrorCodeConfig) EventProcessor {
+	extract := cfg.Extractor
+	if extract == nil {
+		extract = ErrorCode
+	}
+
+	return func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+		if event.Type != "" || hint == nil {
+			return event
+		}
+		err, ok := hint.OriginalException.(error)
+		if !ok {
+			err, ok = hint.RecoveredException.(error)
+		}
+		if !ok || err == nil {
+			return event
+		}
+
+		code := extract(err)
+		if code == "" {
+			return event
+		}
+		if event.Tags == nil {
+			event.Tags = make(map[string]string)
+		}
+		event.Tags["error_code"] = truncateTag(code)
+		if fingerprint, ok := cfg.Fingerprints[code]; ok {
+			event.Fingerprint = fingerprint
+		}
+		return event
+	}
+}
+
+// registerErrorCodes adds the error code processor if enabled
+func registerErrorCodes(cfg Config) {
+	if cfg.ErrorCodes.Enabled {
+		RegisterProcessor("sentrykit.error_codes", errorCodeProcessor(cfg.ErrorCodes), errorCodePriority)
+	}
+}
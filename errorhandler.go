@@ -0,0 +1,123 @@
+package sentrykit
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+)
+
+// capturedEventKey holds the ID of the event captured for the request error
+const capturedEventKey = "sentry_event_id"
+
+// ErrorHandlerConfig holds configuration for the Fiber error handler
+type ErrorHandlerConfig struct {
+	// MinStatus is the lowest response status that is captured (default: 500)
+	MinStatus int
+
+	// Render writes the error response (default: fiber.DefaultErrorHandler)
+	Render fiber.ErrorHandler
+
+	// DisableRouteGrouping keeps sentry-go's default grouping for
+	// fiber.Errors instead of prepending the route template to the fingerprint
+	DisableRouteGrouping bool
+}
+
+// NewErrorHandler creates a fiber.ErrorHandler that captures the error on the
+// request hub, tags it with the response status and then renders the
+// response. Errors already captured by the middleware, and panics captured
+// by RecoverConfig, aren't captured again.
+// The error handler runs after the middleware returned, so a panic in
+// Render is captured here and answered with a 500.
+//
+//	app := fiber.New(fiber.Config{ErrorHandler: sentrykit.NewErrorHandler()})
+func NewErrorHandler(config ...ErrorHandlerConfig) fiber.ErrorHandler {
+	var cfg ErrorHandlerConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.MinStatus == 0 {
+		cfg.MinStatus = fiber.StatusInternalServerError
+	}
+	if cfg.Render == nil {
+		cfg.Render = fiber.DefaultErrorHandler
+	}
+
+	return func(c fiber.Ctx, err error) (renderErr error) {
+		if c.Locals(strictLocalsKey) != nil {
+			// Deferred first, so it runs after rendering
+			defer clearLocals(c)
+		}
+
+		code := statusFromError(err)
+
+		captured := c.Locals(capturedEventKey) != nil || c.Locals(recoveredPanicKey) != nil
+		if code >= cfg.MinStatus && !captured {
+			hub := GetHubFromContext(c)
+			hub.WithScope(func(scope *sentry.Scope) {
+				scope.SetTag("status_code", strconv.Itoa(code))
+				scope.SetTag("route", c.Route().Path)
+				if !cfg.DisableRouteGrouping && isFiberError(err) {
+					scope.SetFingerprint([]string{c.Route().Path, "{{ default }}"})
+				}
+				if eventID := hub.CaptureException(err); eventID != nil {
+					c.Locals(capturedEventKey, eventID)
+				}
+			})
+		}
+
+		defer func() {
+			if err := recover(); err != nil {
+				recoverAfterHandler(c.UserContext(), GetHubFromContext(c), err, "error_handler")
+				renderErr = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}()
+
+		return cfg.Render(c, err)
+	}
+}
+
+// problemDetails is an RFC 7807 error body extended with Sentry identifiers
+type problemDetails struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	TraceID  string `json:"trace_id,omitempty"`
+	EventID  string `json:"event_id,omitempty"`
+}
+
+// WriteError captures server errors (5xx) on the request hub and writes a
+// problem-details JSON body including the trace and event IDs, so clients
+// can quote them in support requests. Details of server errors are not
+// exposed in the body.
+func WriteError(c fiber.Ctx, status int, err error) error {
+	body := problemDetails{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Instance: c.Path(),
+		TraceID:  requestTraceID(c),
+	}
+
+	if status >= fiber.StatusInternalServerError {
+		if eventID := CaptureExceptionFromContext(c, err); eventID != nil {
+			c.Locals(capturedEventKey, eventID)
+			body.EventID = string(*eventID)
+		}
+	} else if err != nil {
+		body.Detail = err.Error()
+	}
+
+	return c.Status(status).JSON(body, "application/problem+json")
+}
+
+// requestTraceID returns the trace ID of the request transaction, if any
+func requestTraceID(c fiber.Ctx) string {
+	if transaction := sentry.TransactionFromContext(c.UserContext()); transaction != nil {
+		return transaction.TraceID.String()
+	}
+	return ""
+}
@@ -0,0 +1,173 @@
+package sentrykit
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+)
+
+// DefaultBrowserSDKURL is the Sentry browser SDK bundle loaded by the error
+// page for the feedback dialog
+const DefaultBrowserSDKURL = "https://browser.sentry-cdn.com/8.55.0/bundle.min.js"
+
+// ErrorPageConfig configures the HTML error page
+type ErrorPageConfig struct {
+	// Title and Message shown on the page (default: "Something went wrong"
+	// and a short apology)
+	Title   string
+	Message string
+
+	// LogoURL, BrandColor (any CSS color) and SupportEmail brand the page
+	// (optional)
+	LogoURL      string
+	BrandColor   string
+	SupportEmail string
+
+	// DSN is the public DSN used by the feedback dialog (default: the DSN
+	// of the global client)
+	DSN string
+
+	// SDKURL is the Sentry browser SDK bundle (default: DefaultBrowserSDKURL)
+	SDKURL string
+
+	// DisableFeedback hides the feedback button
+	DisableFeedback bool
+
+	// Template replaces the built-in page; it is executed with ErrorPageData
+	Template *template.Template
+}
+
+// ErrorPageData is what the error page template renders
+type ErrorPageData struct {
+	Status       int
+	Title        string
+	Message      string
+	EventID      string
+	LogoURL      string
+	BrandColor   string
+	SupportEmail string
+	DSN          string
+	SDKURL       string
+	Feedback     bool
+}
+
+// ErrorPage renders an HTML error page showing the event ID, with a button
+// opening Sentry's user feedback dialog for that event
+type ErrorPage struct {
+	cfg ErrorPageConfig
+}
+
+// NewErrorPage creates an error page renderer
+//
+//	page := sentrykit.NewErrorPage(sentrykit.ErrorPageConfig{BrandColor: "#4f46e5"})
+//	app.Use(sentrykit.New(sentrykit.MiddlewareConfig{PanicHandler: page.RenderPanic}))
+//	app := fiber.New(fiber.Config{ErrorHandler: sentrykit.NewErrorHandler(sentrykit.ErrorHandlerConfig{Render: page.Render})})
+func NewErrorPage(config ...ErrorPageConfig) *ErrorPage {
+	var cfg ErrorPageConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Title == "" {
+		cfg.Title = "Something went wrong"
+	}
+	if cfg.Message == "" {
+		cfg.Message = "We have been notified and are looking into it. Please try again later."
+	}
+	if cfg.SDKURL == "" {
+		cfg.SDKURL = DefaultBrowserSDKURL
+	}
+	if cfg.Template == nil {
+		cfg.Template = defaultErrorPageTemplate
+	}
+	return &ErrorPage{cfg: cfg}
+}
+
+// Render is a fiber.ErrorHandler rendering the page for err, e.g. as
+// ErrorHandlerConfig.Render. The event ID is the one captured for the
+// request, if any.
+func (p *ErrorPage) Render(c fiber.Ctx, err error) error {
+	eventID, _ := c.Locals(capturedEventKey).(*sentry.EventID)
+	return p.render(c, statusFromError(err), eventID)
+}
+
+// RenderPanic renders a 500 page for a recovered panic, for use as
+// MiddlewareConfig.PanicHandler
+func (p *ErrorPage) RenderPanic(c fiber.Ctx, eventID *sentry.EventID) error {
+	return p.render(c, fiber.StatusInternalServerError, eventID)
+}
+
+// render writes the page with status, offering feedback when an event was
+// captured and a DSN is known
+func (p *ErrorPage) render(c fiber.Ctx, status int, eventID *sentry.EventID) error {
+	data := ErrorPageData{
+		Status:       status,
+		Title:        p.cfg.Title,
+		Message:      p.cfg.Message,
+		LogoURL:      p.cfg.LogoURL,
+		BrandColor:   p.cfg.BrandColor,
+		SupportEmail: p.cfg.SupportEmail,
+		DSN:          p.cfg.DSN,
+		SDKURL:       p.cfg.SDKURL,
+	}
+	if status < fiber.StatusInternalServerError {
+		data.Title = http.StatusText(status)
+		data.Message = ""
+	}
+	if eventID != nil {
+		data.EventID = string(*eventID)
+	}
+	if data.DSN == "" {
+		if client := GetHubFromContext(c).Client(); client != nil {
+			data.DSN = client.Options().Dsn
+		}
+	}
+	data.Feedback = !p.cfg.DisableFeedback && data.EventID != "" && data.DSN != ""
+
+	var buf bytes.Buffer
+	if err := p.cfg.Template.Execute(&buf, data); err != nil {
+		return c.SendStatus(status)
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.Status(status).Send(buf.Bytes())
+}
+
+// defaultErrorPageTemplate is the built-in error page
+var defaultErrorPageTemplate = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; background: #f6f6f7; color: #2b2b33; display: flex; align-items: center; justify-content: center; min-height: 100vh; margin: 0; }
+main { background: #fff; border-radius: 8px; box-shadow: 0 1px 4px rgba(0,0,0,.1); padding: 2.5rem; max-width: 32rem; text-align: center; }
+img { max-height: 3rem; margin-bottom: 1rem; }
+code { background: #f0f0f2; border-radius: 4px; padding: .1rem .3rem; }
+button { background: {{if .BrandColor}}{{.BrandColor}}{{else}}#6c5fc7{{end}}; border: 0; border-radius: 4px; color: #fff; cursor: pointer; font-size: 1rem; margin-top: 1rem; padding: .6rem 1.2rem; }
+</style>
+</head>
+<body>
+<main>
+{{if .LogoURL}}<img src="{{.LogoURL}}" alt="">{{end}}
+<h1>{{.Title}}</h1>
+{{if .Message}}<p>{{.Message}}</p>{{end}}
+{{if .EventID}}<p>Error reference: <code>{{.EventID}}</code></p>{{end}}
+{{if .SupportEmail}}<p>Need help? Contact <a href="mailto:{{.SupportEmail}}">{{.SupportEmail}}</a>{{if .EventID}} and quote the reference above{{end}}.</p>{{end}}
+{{if .Feedback}}<button id="sentry-feedback" type="button">Tell us what happened</button>{{end}}
+</main>
+{{if .Feedback}}
+<script src="{{.SDKURL}}" crossorigin="anonymous"></script>
+<script>
+Sentry.init({ dsn: {{.DSN}} });
+document.getElementById("sentry-feedback").addEventListener("click", function () {
+  Sentry.showReportDialog({ eventId: {{.EventID}} });
+});
+</script>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,92 @@
+package sentrykit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// EventRecord is the local record of an event sent to Sentry
+type EventRecord struct {
+	Time    time.Time      `json:"time"`
+	Route   string         `json:"route,omitempty"`
+	EventID sentry.EventID `json:"event_id"`
+	Level   sentry.Level   `json:"level,omitempty"`
+
+	// Type is empty for error and message events, e.g. "transaction" otherwise
+	Type string `json:"type,omitempty"`
+}
+
+// EventLog persists a record of each event sent to Sentry, e.g. as an
+// on-prem audit trail for compliance. Append is called from the delivery
+// worker after each send; failures are recorded as event_log internal
+// errors.
+type EventLog interface {
+	Append(record EventRecord) error
+}
+
+// FileEventLog is an append-only EventLog writing one JSON record per line
+type FileEventLog struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileEventLog opens or creates the log file at path for appending
+func NewFileEventLog(path string) (*FileEventLog, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event log: %w", err)
+	}
+	return &FileEventLog{file: file}, nil
+}
+
+// Append writes the record as a JSON line
+func (l *FileEventLog) Append(record EventRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.file.Write(line)
+	return err
+}
+
+// Close closes the log file
+func (l *FileEventLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// eventRecord describes a sent event. Error events have no transaction
+// name, so their route comes from the request tags.
+func eventRecord(event *sentry.Event) EventRecord {
+	route := event.Transaction
+	if route == "" {
+		route = event.Tags["route"]
+	}
+	if route == "" {
+		route = event.Tags["path"]
+	}
+	return EventRecord{
+		Time:    now(),
+		Route:   route,
+		EventID: event.EventID,
+		Level:   event.Level,
+		Type:    event.Type,
+	}
+}
+
+// appendEventLog records a sent event in log
+func appendEventLog(log EventLog, event *sentry.Event) {
+	if err := log.Append(eventRecord(event)); err != nil {
+		reportInternal("event_log", err.Error())
+	}
+}
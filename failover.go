@@ -0,0 +1,157 @@
+package sentrykit
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// defaultFailoverCooldown is how long a failed endpoint is skipped before
+// it is probed again
+const defaultFailoverCooldown = 30 * time.Second
+
+// failoverEndpoint is one ingest endpoint in priority order
+type failoverEndpoint struct {
+	name string
+
+	// base replaces the scheme, host and path prefix of requests (nil =
+	// send to the DSN host unchanged)
+	base *url.URL
+
+	// dsn switches the project and key of requests for a failover DSN
+	dsn *sentry.Dsn
+
+	mu       sync.Mutex
+	failures int
+	retryAt  time.Time
+}
+
+// parseFailoverEndpoint reads a failover entry: a DSN when it carries a
+// key, otherwise an ingest base URL like TransportConfig.Endpoint
+func parseFailoverEndpoint(raw string) (*failoverEndpoint, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("Transport.Failover entries must be DSNs or absolute URLs, got %q", raw)
+	}
+	if u.User == nil {
+		return &failoverEndpoint{name: u.Host, base: u}, nil
+	}
+	dsn, err := sentry.NewDsn(raw)
+	if err != nil {
+		return nil, fmt.Errorf("Transport.Failover: invalid DSN: %w", err)
+	}
+	return &failoverEndpoint{name: dsn.GetHost(), dsn: dsn}, nil
+}
+
+// available reports whether the endpoint is healthy or due for a probe
+func (e *failoverEndpoint) available(at time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.failures == 0 || !at.Before(e.retryAt)
+}
+
+// record updates the endpoint health after a delivery attempt. It returns
+// true when the endpoint just became unhealthy.
+func (e *failoverEndpoint) record(ok bool, cooldown time.Duration) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if ok {
+		e.failures = 0
+		return false
+	}
+	e.failures++
+	e.retryAt = now().Add(cooldown)
+	return e.failures == 1
+}
+
+// sentryKeyPattern matches the key and secret of the X-Sentry-Auth header
+var sentryKeyPattern = regexp.MustCompile(`(, )?sentry_(key|secret)=[^,]*`)
+
+// rewrite points req at the endpoint
+func (e *failoverEndpoint) rewrite(req *http.Request) {
+	switch {
+	case e.dsn != nil:
+		api := e.dsn.GetAPIURL()
+		req.URL.Scheme = api.Scheme
+		req.URL.Host = api.Host
+		req.URL.Path = api.Path
+		req.URL.RawPath = ""
+		req.Host = api.Host
+
+		auth := sentryKeyPattern.ReplaceAllString(req.Header.Get("X-Sentry-Auth"), "")
+		auth += ", sentry_key=" + e.dsn.GetPublicKey()
+		if secret := e.dsn.GetSecretKey(); secret != "" {
+			auth += ", sentry_secret=" + secret
+		}
+		req.Header.Set("X-Sentry-Auth", auth)
+	case e.base != nil:
+		req.URL.Scheme = e.base.Scheme
+		req.URL.Host = e.base.Host
+		req.URL.Path = strings.TrimSuffix(e.base.Path, "/") + req.URL.Path
+		req.URL.RawPath = ""
+		req.Host = e.base.Host
+	}
+}
+
+// failoverTransport sends each request to the first healthy endpoint in
+// priority order, moving on when one fails. A failed endpoint is skipped
+// for the cooldown, then probed again with the next request, so delivery
+// returns to the preferred endpoint once it recovers.
+type failoverTransport struct {
+	base      http.RoundTripper
+	endpoints []*failoverEndpoint
+	cooldown  time.Duration
+}
+
+// RoundTrip tries available endpoints first, then the remaining ones, until
+// one accepts the request
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	current := now()
+	order := make([]*failoverEndpoint, 0, len(t.endpoints))
+	for _, endpoint := range t.endpoints {
+		if endpoint.available(current) {
+			order = append(order, endpoint)
+		}
+	}
+	for _, endpoint := range t.endpoints {
+		if !endpoint.available(current) {
+			order = append(order, endpoint)
+		}
+	}
+
+	var (
+		resp *http.Response
+		err  error
+	)
+	for i, endpoint := range order {
+		attempt := req.Clone(req.Context())
+		if i > 0 && req.GetBody != nil {
+			if attempt.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		endpoint.rewrite(attempt)
+
+		resp, err = t.base.RoundTrip(attempt)
+		failed := err != nil || resp.StatusCode >= http.StatusInternalServerError
+		if endpoint.record(!failed, t.cooldown) {
+			reportInternal("failover", fmt.Sprintf("ingest endpoint %s failed, failing over", endpoint.name))
+		}
+
+		// Without a replayable body, only the first endpoint can be tried
+		last := i == len(order)-1 || req.GetBody == nil
+		if !failed || last {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+	}
+	return resp, err
+}
@@ -0,0 +1,109 @@
+package sentrykit
+
+import (
+	"fmt"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+)
+
+// maxFanOutIndexes caps the failed item indexes listed in the summary event
+const maxFanOutIndexes = 100
+
+// FanOutConfig configures FanOut
+type FanOutConfig struct {
+	// MaxItemEvents caps the events captured for failed items; further
+	// failures only count towards the summary (default: 10)
+	MaxItemEvents int
+}
+
+// FanOutResult is the outcome of a batch processed with FanOut
+type FanOutResult struct {
+	// Total and Failed count the items
+	Total  int
+	Failed int
+
+	// Errors holds the error of each item by index, nil for successes
+	Errors []error
+
+	// SummaryEventID is the ID of the summary event, nil when no item failed
+	SummaryEventID *sentry.EventID
+}
+
+// FanOut processes count items of a batch endpoint with fn, each on a child
+// hub (see WithChildHub) carrying a "batch_item" context with the batch
+// name and item index. Each failed item is captured as its own event, up to
+// MaxItemEvents, followed by a summary warning with the aggregate counts,
+// or an error when all items failed. fn can tag the item on hub.
+//
+//	result := sentrykit.FanOut(c, "orders.import", len(orders), func(i int, hub *sentry.Hub) error {
+//		hub.Scope().SetTag("order_id", orders[i].ID)
+//		return importOrder(orders[i])
+//	})
+func FanOut(c fiber.Ctx, name string, count int, fn func(i int, hub *sentry.Hub) error, config ...FanOutConfig) FanOutResult {
+	var cfg FanOutConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.MaxItemEvents <= 0 {
+		cfg.MaxItemEvents = 10
+	}
+
+	result := FanOutResult{Total: count, Errors: make([]error, count)}
+	var (
+		failedIndexes []int
+		itemEvents    []string
+	)
+
+	for i := 0; i < count; i++ {
+		WithChildHub(c, func(hub *sentry.Hub) {
+			hub.Scope().SetTag("batch", name)
+			hub.Scope().SetContext("batch_item", map[string]interface{}{
+				"batch": name,
+				"index": i,
+				"total": count,
+			})
+
+			err := fn(i, hub)
+			if err == nil {
+				return
+			}
+			result.Errors[i] = err
+			result.Failed++
+			if len(failedIndexes) < maxFanOutIndexes {
+				failedIndexes = append(failedIndexes, i)
+			}
+			if len(itemEvents) < cfg.MaxItemEvents {
+				if eventID := hub.CaptureException(err); eventID != nil {
+					itemEvents = append(itemEvents, string(*eventID))
+				}
+			}
+		})
+	}
+
+	if result.Failed == 0 {
+		return result
+	}
+
+	level := sentry.LevelWarning
+	if result.Failed == count {
+		level = sentry.LevelError
+	}
+
+	hub := GetHubFromContext(c)
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetLevel(level)
+		scope.SetTag("batch", name)
+		scope.SetFingerprint([]string{"batch", name})
+		scope.SetContext("batch", map[string]interface{}{
+			"batch":          name,
+			"total":          count,
+			"failed":         result.Failed,
+			"succeeded":      count - result.Failed,
+			"failed_indexes": failedIndexes,
+			"item_events":    itemEvents,
+		})
+		result.SummaryEventID = hub.CaptureMessage(fmt.Sprintf("Batch %s: %d of %d items failed", name, result.Failed, count))
+	})
+	return result
+}
@@ -0,0 +1,112 @@
+package sentrykit
+
+import (
+	"github.com/getsentry/sentry-go"
+	"github.com/valyala/fasthttp"
+)
+
+// fasthttpStateKey is the user value key holding the request state
+const fasthttpStateKey = "sentry_request_state"
+
+// NewFastHTTP wraps a fasthttp.RequestHandler with the same core as the
+// Fiber middleware (hub per request, request context, tracing and panic
+// recovery), for components using fasthttp without Fiber.
+func NewFastHTTP(next fasthttp.RequestHandler, config ...MiddlewareConfig) fasthttp.RequestHandler {
+	cfg := middlewareConfig(config...)
+
+	captureHeader := headerFilter(cfg)
+	isHealthcheck := healthcheckFilter(cfg)
+
+	return func(ctx *fasthttp.RequestCtx) {
+		if isHealthcheck(string(ctx.Path())) {
+			next(ctx)
+			return
+		}
+		if !clientBound(cfg) {
+			serveFastHTTPUnreported(next, ctx, cfg)
+			return
+		}
+
+		if cfg.StrictLocals {
+			// Deferred first, so it runs after the request is finished
+			defer ctx.RemoveUserValue(fasthttpStateKey)
+		}
+
+		state := startRequest(ctx, cfg, fasthttpRequestInfo(ctx, captureHeader), nil)
+		defer state.end()
+
+		// Store request state for later use
+		ctx.SetUserValue(fasthttpStateKey, state)
+
+		// Recover from panics
+		defer func() {
+			if err := recover(); err != nil {
+				if !cfg.Repanic {
+					ctx.Error(fasthttp.StatusMessage(fasthttp.StatusInternalServerError), fasthttp.StatusInternalServerError)
+				}
+				state.recoverPanic(state.context(), err)
+			}
+		}()
+
+		// Process request
+		next(ctx)
+
+		state.finish(state.context(), "", ctx.Response.StatusCode(), nil)
+	}
+}
+
+// GetHubFromFastHTTP retrieves the Sentry hub from a fasthttp request context
+func GetHubFromFastHTTP(ctx *fasthttp.RequestCtx) *sentry.Hub {
+	if state, ok := ctx.UserValue(fasthttpStateKey).(*requestState); ok {
+		return state.requestHub()
+	}
+	return sentry.CurrentHub()
+}
+
+// fasthttpRequestInfo describes a fasthttp request for the shared core
+func fasthttpRequestInfo(ctx *fasthttp.RequestCtx, captureHeader func(key string) bool) requestInfo {
+	headers := make(map[string]string)
+	ctx.Request.Header.VisitAll(func(key, value []byte) {
+		keyStr := string(key)
+		// Skip sensitive or non-allowlisted headers
+		if captureHeader(keyStr) {
+			headers[keyStr] = string(value)
+		}
+	})
+
+	return requestInfo{
+		URL:         string(ctx.RequestURI()),
+		Method:      string(ctx.Method()),
+		Path:        string(ctx.Path()),
+		Query:       string(ctx.QueryArgs().QueryString()),
+		Headers:     headers,
+		IP:          ctx.RemoteIP().String(),
+		UserAgent:   string(ctx.UserAgent()),
+		SentryTrace: string(ctx.Request.Header.Peek(sentry.SentryTraceHeader)),
+		Baggage:     string(ctx.Request.Header.Peek(sentry.SentryBaggageHeader)),
+		Header: func(name string) string {
+			return string(ctx.Request.Header.Peek(name))
+		},
+		Cookie: func(name string) string {
+			return string(ctx.Request.Header.Cookie(name))
+		},
+		BaseURL: string(ctx.URI().Scheme()) + "://" + string(ctx.Host()),
+		Body:    ctx.PostBody,
+		Response: func() responseInfo {
+			return fasthttpResponseInfo(&ctx.Response)
+		},
+	}
+}
+
+// fasthttpResponseInfo describes a fasthttp response, copying the buffers
+// fasthttp reuses
+func fasthttpResponseInfo(resp *fasthttp.Response) responseInfo {
+	headers := make(map[string]string)
+	resp.Header.VisitAll(func(key, value []byte) {
+		headers[string(key)] = string(value)
+	})
+	return responseInfo{
+		Headers: headers,
+		Body:    append([]byte{}, resp.Body()...),
+	}
+}
@@ -0,0 +1,54 @@
+package sentrykit
+
+import (
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// DefaultInternalFramePrefixes are the module prefixes stripped from the top
+// of stack traces when StripInternalFrames is enabled
+var DefaultInternalFramePrefixes = []string{
+	"github.com/purwadarozatun/go-sentry-fiber-3",
+	"github.com/gofiber/fiber/v3",
+	"github.com/valyala/fasthttp",
+	"runtime",
+}
+
+// stripFramesProcessor returns an event processor that removes frames whose
+// module matches one of the prefixes from the top of captured stack traces
+func stripFramesProcessor(prefixes []string) EventProcessor {
+	return func(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+		for i := range event.Exception {
+			if st := event.Exception[i].Stacktrace; st != nil {
+				st.Frames = stripTopFrames(st.Frames, prefixes)
+			}
+		}
+		for i := range event.Threads {
+			if st := event.Threads[i].Stacktrace; st != nil {
+				st.Frames = stripTopFrames(st.Frames, prefixes)
+			}
+		}
+		return event
+	}
+}
+
+// stripTopFrames drops matching frames from the most recent end of the stack.
+// Frames are ordered oldest first, and at least one frame is always kept.
+func stripTopFrames(frames []sentry.Frame, prefixes []string) []sentry.Frame {
+	end := len(frames)
+	for end > 1 && isInternalFrame(frames[end-1], prefixes) {
+		end--
+	}
+	return frames[:end]
+}
+
+// isInternalFrame reports whether the frame's module matches one of the prefixes
+func isInternalFrame(frame sentry.Frame, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if frame.Module == prefix || strings.HasPrefix(frame.Module, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
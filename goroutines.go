@@ -0,0 +1,162 @@
+package sentrykit
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// GoroutineMonitorConfig configures goroutine leak monitoring
+type GoroutineMonitorConfig struct {
+	// Interval between goroutine count samples (default: 30s)
+	Interval time.Duration
+
+	// MaxGrowth is the growth over the lowest count seen that is reported
+	// as a leak (default: 1000)
+	MaxGrowth int
+
+	// MaxGoroutines reports whenever the count exceeds it, regardless of
+	// growth (0 = disabled)
+	MaxGoroutines int
+
+	// TopSites is the number of creation sites listed in the event
+	// (default: 10)
+	TopSites int
+
+	// Cooldown is the minimum time between two reports (default: 10m)
+	Cooldown time.Duration
+}
+
+// StartGoroutineMonitor samples the goroutine count and captures a warning
+// when it grows beyond the thresholds, catching leaks from handlers that
+// never clean up. The event's "goroutines" context lists the top creation
+// sites by goroutine count, and a "goroutines.txt" attachment has the
+// stacks grouped by pprof. After a report the baseline moves to the current
+// count. Call the returned function to stop.
+func StartGoroutineMonitor(config ...GoroutineMonitorConfig) (stop func()) {
+	var cfg GoroutineMonitorConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 30 * time.Second
+	}
+	if cfg.MaxGrowth <= 0 {
+		cfg.MaxGrowth = 1000
+	}
+	if cfg.TopSites <= 0 {
+		cfg.TopSites = 10
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 10 * time.Minute
+	}
+
+	limiter := &cooldown{interval: cfg.Cooldown}
+	baseline := runtime.NumGoroutine()
+
+	return startTicker(cfg.Interval, func() {
+		count := runtime.NumGoroutine()
+		if count < baseline {
+			baseline = count
+		}
+
+		growth := count - baseline
+		overLimit := cfg.MaxGoroutines > 0 && count > cfg.MaxGoroutines
+		if growth < cfg.MaxGrowth && !overLimit {
+			return
+		}
+		if !limiter.allow() {
+			return
+		}
+
+		captureDiagnostic(sentry.CurrentHub(), diagnostic{
+			message:     fmt.Sprintf("Goroutine count grew by %d to %d", growth, count),
+			fingerprint: []string{"goroutines", "leak"},
+			context: map[string]interface{}{
+				"count":          count,
+				"baseline":       baseline,
+				"growth":         growth,
+				"max_growth":     cfg.MaxGrowth,
+				"max_goroutines": cfg.MaxGoroutines,
+				"creation_sites": topCreationSites(cfg.TopSites),
+			},
+			attachments: []*sentry.Attachment{groupedGoroutineProfile()},
+		})
+		baseline = count
+	})
+}
+
+// topCreationSites returns the creation sites with the most goroutines as
+// "count site" strings, most first
+func topCreationSites(limit int) []string {
+	dump := goroutineDump().Payload
+
+	counts := make(map[string]int)
+	for _, block := range bytes.Split(dump, []byte("\n\n")) {
+		counts[creationSite(string(block))]++
+	}
+
+	sites := make([]string, 0, len(counts))
+	for site := range counts {
+		sites = append(sites, site)
+	}
+	sort.Slice(sites, func(i, j int) bool {
+		if counts[sites[i]] != counts[sites[j]] {
+			return counts[sites[i]] > counts[sites[j]]
+		}
+		return sites[i] < sites[j]
+	})
+	if len(sites) > limit {
+		sites = sites[:limit]
+	}
+
+	result := make([]string, len(sites))
+	for i, site := range sites {
+		result[i] = fmt.Sprintf("%d %s", counts[site], site)
+	}
+	return result
+}
+
+// creationSite returns the "created by" function and location of a
+// goroutine stack from runtime.Stack
+func creationSite(stack string) string {
+	lines := strings.Split(stack, "\n")
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "created by ") {
+			continue
+		}
+		site := strings.TrimPrefix(line, "created by ")
+		if j := strings.Index(site, " in goroutine "); j >= 0 {
+			site = site[:j]
+		}
+		if i+1 < len(lines) {
+			location := strings.TrimSpace(lines[i+1])
+			if j := strings.LastIndex(location, " +0x"); j >= 0 {
+				location = location[:j]
+			}
+			site += " (" + location + ")"
+		}
+		return site
+	}
+	return "(no creator, e.g. main)"
+}
+
+// groupedGoroutineProfile returns the goroutine profile with identical
+// stacks grouped and counted, as an attachment
+func groupedGoroutineProfile() *sentry.Attachment {
+	var buf bytes.Buffer
+	if profile := pprof.Lookup("goroutine"); profile != nil {
+		_ = profile.WriteTo(&buf, 1)
+	}
+	return &sentry.Attachment{
+		Filename:    "goroutines.txt",
+		ContentType: "text/plain",
+		Payload:     buf.Bytes(),
+	}
+}
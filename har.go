@@ -0,0 +1,228 @@
+package sentrykit
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// responseInfo describes the response of a request at capture time
+type responseInfo struct {
+	Headers map[string]string
+
+	// Body is nil when the framework doesn't buffer the response (net/http)
+	Body []byte
+}
+
+// harNameValue is a HAR header or query parameter
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// harPostData is the body of a HAR request
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// harContent is the body of a HAR response
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// harRequest is the request of a HAR entry
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+	Comment     string         `json:"comment,omitempty"`
+}
+
+// harResponse is the response of a HAR entry
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+// harTimings are the phases of a HAR entry in milliseconds; the server
+// only knows the handler time, reported as wait
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// harEntry is one request/response pair
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+// harLog is a HAR 1.2 document
+type harLog struct {
+	Log struct {
+		Version string `json:"version"`
+		Creator struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"creator"`
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+// har builds a HAR document with the request/response pair, sanitized like
+// the replay bundle
+func (r *requestState) har() harLog {
+	bundle := r.replayBundle()
+	elapsed := milliseconds(since(r.start))
+
+	request := harRequest{
+		Method:      bundle.Method,
+		URL:         bundle.URL,
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(bundle.Headers),
+		QueryString: []harNameValue{},
+		HeadersSize: -1,
+		BodySize:    len(bundle.Body),
+	}
+	if u, err := url.Parse(bundle.URL); err == nil {
+		for _, pair := range strings.Split(u.RawQuery, "&") {
+			if key, value, ok := strings.Cut(pair, "="); ok {
+				key, _ = url.QueryUnescape(key)
+				value, _ = url.QueryUnescape(value)
+				request.QueryString = append(request.QueryString, harNameValue{Name: key, Value: value})
+			}
+		}
+	}
+	if bundle.Body != "" {
+		request.PostData = &harPostData{MimeType: bundle.Headers["Content-Type"], Text: bundle.Body}
+	}
+	if bundle.BodyTruncated {
+		request.Comment = "body truncated"
+	} else if bundle.BodyOmitted != "" {
+		request.Comment = "body omitted: " + bundle.BodyOmitted
+	}
+
+	status := r.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	response := harResponse{
+		Status:      status,
+		StatusText:  http.StatusText(status),
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []harNameValue{},
+		Headers:     []harNameValue{},
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+	if r.req.Response != nil {
+		resp := r.req.Response()
+		headers := make(map[string]string, len(resp.Headers))
+		for key, value := range resp.Headers {
+			if strings.EqualFold(key, "Set-Cookie") {
+				value = filteredValue
+			}
+			headers[key] = value
+		}
+		response.Headers = harHeaders(headers)
+		response.Content = r.harContent(headers["Content-Type"], resp.Body)
+		if resp.Body != nil {
+			response.BodySize = len(resp.Body)
+		}
+	}
+
+	var doc harLog
+	doc.Log.Version = "1.2"
+	// The kit has no version of its own; report the SDK it runs on
+	doc.Log.Creator.Name = "sentrykit"
+	doc.Log.Creator.Version = sentry.SDKVersion
+	doc.Log.Entries = []harEntry{{
+		StartedDateTime: r.start.UTC().Format(time.RFC3339Nano),
+		Time:            elapsed,
+		Request:         request,
+		Response:        response,
+		Timings:         harTimings{Wait: elapsed},
+	}}
+	return doc
+}
+
+// harContent describes the response body, redacted and truncated like
+// replayed request bodies
+func (r *requestState) harContent(contentType string, body []byte) harContent {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	content := harContent{Size: len(body), MimeType: mediaType}
+	if body == nil {
+		content.Comment = "body unavailable"
+		return content
+	}
+	if len(body) == 0 {
+		return content
+	}
+
+	text, ok := redactBody(contentType, body, r.replayDenylist())
+	switch {
+	case !ok && mediaType == "text/html":
+		text = string(body)
+	case !ok:
+		content.Comment = "body omitted: unsupported content type"
+		return content
+	}
+	if maxBody := r.replayMaxBody(); len(text) > maxBody {
+		text = truncateUTF8(text, maxBody)
+		content.Comment = "body truncated"
+	}
+	content.Text = text
+	return content
+}
+
+// harHeaders converts headers to sorted HAR name/value pairs
+func harHeaders(headers map[string]string) []harNameValue {
+	pairs := make([]harNameValue, 0, len(headers))
+	for key, value := range headers {
+		pairs = append(pairs, harNameValue{Name: key, Value: value})
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].Name < pairs[j].Name })
+	return pairs
+}
+
+// harAttachment renders the request's HAR document as "request.har"
+func (r *requestState) harAttachment() *sentry.Attachment {
+	payload, err := json.MarshalIndent(r.har(), "", "  ")
+	if err != nil {
+		return nil
+	}
+	return &sentry.Attachment{
+		Filename:    "request.har",
+		ContentType: "application/json",
+		Payload:     payload,
+	}
+}
@@ -0,0 +1,48 @@
+package sentrykit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// testDSN is a well-formed DSN; events go to the test transport instead
+const testDSN = "https://key@o1.ingest.sentry.io/1"
+
+// bindTestClient binds a client recording its events, transactions
+// included, to the current hub for the duration of the test
+func bindTestClient(t *testing.T) *sentry.MockTransport {
+	t.Helper()
+
+	transport := &sentry.MockTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:              testDSN,
+		Transport:        transport,
+		EnableTracing:    true,
+		TracesSampleRate: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hub := sentry.CurrentHub()
+	previous := hub.Client()
+	hub.BindClient(client)
+	t.Cleanup(func() { hub.BindClient(previous) })
+	return transport
+}
+
+// waitForEvent polls transport until an event matches or timeout passes
+func waitForEvent(transport *sentry.MockTransport, timeout time.Duration, match func(*sentry.Event) bool) *sentry.Event {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		for _, event := range transport.Events() {
+			if match(event) {
+				return event
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return nil
+}
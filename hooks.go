@@ -0,0 +1,44 @@
+package sentrykit
+
+import (
+	"bufio"
+	"context"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+	"github.com/valyala/fasthttp"
+)
+
+// StreamWriter wraps a response body stream writer so a panic while
+// streaming is captured on the request hub with the request's context.
+// Stream writers run on their own goroutine after the handler chain
+// returned, outside the middleware's recovery, where a panic would
+// otherwise crash the process without any request data. The response
+// stream ends at the panic.
+//
+//	c.Response().SetBodyStreamWriter(sentrykit.StreamWriter(c, func(w *bufio.Writer) {
+//	    ...
+//	}))
+func StreamWriter(c fiber.Ctx, fn func(w *bufio.Writer)) fasthttp.StreamWriter {
+	// The Fiber context is released before the writer runs
+	hub := GetHubFromContext(c)
+	ctx := c.UserContext()
+
+	return func(w *bufio.Writer) {
+		defer func() {
+			if err := recover(); err != nil {
+				recoverAfterHandler(ctx, hub, err, "stream_writer")
+			}
+		}()
+		fn(w)
+	}
+}
+
+// recoverAfterHandler reports a panic from code running after the handler
+// chain, tagged with the phase it happened in
+func recoverAfterHandler(ctx context.Context, hub *sentry.Hub, err interface{}, phase string) {
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetTag("panic_phase", phase)
+		recoverValue(ctx, hub, err)
+	})
+}
@@ -0,0 +1,216 @@
+package sentrykit
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// NewHTTP creates Sentry middleware for net/http handlers. It shares the
+// Fiber middleware's core and configuration semantics: hub per request,
+// request context, tracing and panic recovery. The request hub is available
+// via sentry.GetHubFromContext(r.Context()). Options working on Fiber's
+// context (StrictLocals, LocalsExtras, SessionStore, TenantExtractor,
+// PanicHandler, CaptureErrorResponses) are Fiber only and ignored here.
+func NewHTTP(config ...MiddlewareConfig) func(http.Handler) http.Handler {
+	cfg := middlewareConfig(config...)
+
+	captureHeader := headerFilter(cfg)
+	isHealthcheck := healthcheckFilter(cfg)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isHealthcheck(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !clientBound(cfg) {
+				serveHTTPUnreported(next, w, r, cfg)
+				return
+			}
+
+			rw := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			info := httpRequestInfo(r, captureHeader)
+			info.Response = rw.responseInfo
+
+			state := startRequest(r.Context(), cfg, info, nil)
+			defer state.end()
+
+			r = r.WithContext(state.context())
+
+			// Recover from panics
+			defer func() {
+				if err := recover(); err != nil {
+					if !cfg.Repanic && !rw.wroteHeader {
+						rw.WriteHeader(http.StatusInternalServerError)
+					}
+					state.recoverPanic(r.Context(), err)
+				}
+			}()
+
+			// Process request
+			next.ServeHTTP(rw.wrap(), r)
+
+			state.firstByte = rw.firstByte
+			state.finish(r.Context(), httpRoute(r), rw.status, nil)
+		})
+	}
+}
+
+// httpRequestInfo describes a net/http request for the shared core
+func httpRequestInfo(r *http.Request, captureHeader func(key string) bool) requestInfo {
+	headers := make(map[string]string, len(r.Header))
+	for key := range r.Header {
+		// Skip sensitive or non-allowlisted headers
+		if captureHeader(key) {
+			headers[key] = r.Header.Get(key)
+		}
+	}
+
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ip = host
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	return requestInfo{
+		URL:         r.URL.String(),
+		Method:      r.Method,
+		Path:        r.URL.Path,
+		Query:       r.URL.RawQuery,
+		Headers:     headers,
+		IP:          ip,
+		UserAgent:   r.UserAgent(),
+		SentryTrace: r.Header.Get(sentry.SentryTraceHeader),
+		Baggage:     r.Header.Get(sentry.SentryBaggageHeader),
+		Header:      r.Header.Get,
+		Cookie: func(name string) string {
+			if cookie, err := r.Cookie(name); err == nil {
+				return cookie.Value
+			}
+			return ""
+		},
+		BaseURL: scheme + "://" + r.Host,
+	}
+}
+
+// httpRoute returns the ServeMux pattern that matched the request without
+// its method prefix, or "" when the request wasn't routed by a ServeMux
+func httpRoute(r *http.Request) string {
+	pattern := r.Pattern
+	if i := strings.IndexByte(pattern, ' '); i >= 0 {
+		pattern = pattern[i+1:]
+	}
+	return pattern
+}
+
+// statusRecorder captures the status code written by a net/http handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	firstByte   time.Time
+}
+
+// WriteHeader records the status code before writing it
+func (w *statusRecorder) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status = code
+		w.wroteHeader = true
+		w.firstByte = now()
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write marks the header as written before writing the body
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.firstByte = now()
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// responseInfo describes the headers written so far; net/http responses
+// are streamed, so there is no body
+func (w *statusRecorder) responseInfo() responseInfo {
+	headers := make(map[string]string, len(w.Header()))
+	for key := range w.Header() {
+		headers[key] = w.Header().Get(key)
+	}
+	return responseInfo{Headers: headers}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// wrap returns the recorder as a writer implementing http.Flusher and
+// http.Hijacker exactly when the underlying writer does, so handlers
+// asserting them (server-sent events, websockets) keep working
+func (w *statusRecorder) wrap() http.ResponseWriter {
+	flusher, canFlush := w.ResponseWriter.(http.Flusher)
+	hijacker, canHijack := w.ResponseWriter.(http.Hijacker)
+
+	switch {
+	case canFlush && canHijack:
+		return struct {
+			*statusRecorder
+			http.Flusher
+			http.Hijacker
+		}{w, recorderFlusher{w, flusher}, recorderHijacker{w, hijacker}}
+	case canFlush:
+		return struct {
+			*statusRecorder
+			http.Flusher
+		}{w, recorderFlusher{w, flusher}}
+	case canHijack:
+		return struct {
+			*statusRecorder
+			http.Hijacker
+		}{w, recorderHijacker{w, hijacker}}
+	default:
+		return w
+	}
+}
+
+// recorderFlusher flushes the underlying writer, which writes the header
+// if the handler didn't
+type recorderFlusher struct {
+	w       *statusRecorder
+	flusher http.Flusher
+}
+
+// Flush marks the header as written and flushes
+func (f recorderFlusher) Flush() {
+	if !f.w.wroteHeader {
+		f.w.wroteHeader = true
+		f.w.firstByte = now()
+	}
+	f.flusher.Flush()
+}
+
+// recorderHijacker hands the connection over to the handler
+type recorderHijacker struct {
+	w        *statusRecorder
+	hijacker http.Hijacker
+}
+
+// Hijack takes over the connection; the response is the handler's from
+// then on, so the middleware won't write a status for a later panic
+func (h recorderHijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := h.hijacker.Hijack()
+	if err == nil {
+		h.w.wroteHeader = true
+	}
+	return conn, rw, err
+}
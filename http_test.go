@@ -0,0 +1,57 @@
+package sentrykit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewHTTPKeepsFlusherAndHijacker(t *testing.T) {
+	bindTestClient(t)
+
+	var canFlush, canHijack bool
+	handler := NewHTTP()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, canFlush = w.(http.Flusher)
+		_, canHijack = w.(http.Hijacker)
+		if canHijack {
+			conn, rw, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer conn.Close()
+			rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n\r\n")
+			rw.Flush()
+		}
+	}))
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if !canFlush || !canHijack {
+		t.Errorf("handler writer Flusher: %v, Hijacker: %v, want both", canFlush, canHijack)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("status = %d, want the hijacked connection's 101", resp.StatusCode)
+	}
+
+	// A writer without Flusher and Hijacker doesn't gain them
+	recorder := &statusRecorder{ResponseWriter: plainWriter{httptest.NewRecorder()}}
+	if _, ok := recorder.wrap().(http.Flusher); ok {
+		t.Error("wrapped plain writer implements http.Flusher")
+	}
+	if _, ok := recorder.wrap().(http.Hijacker); ok {
+		t.Error("wrapped plain writer implements http.Hijacker")
+	}
+}
+
+// plainWriter hides the optional interfaces of a response writer
+type plainWriter struct {
+	http.ResponseWriter
+}
@@ -0,0 +1,82 @@
+package sentrykit
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// InstallConfig configures the pieces wired by Install
+type InstallConfig struct {
+	// Middleware configures the request middleware
+	// (default: DefaultMiddlewareConfig())
+	Middleware MiddlewareConfig
+
+	// ErrorHandler configures error capture; Render defaults to the app's
+	// own error handler, so responses are unchanged
+	ErrorHandler ErrorHandlerConfig
+
+	// ReportRoutes sends a route snapshot (see ReportRoutes) when the app
+	// starts listening, configured by Routes
+	ReportRoutes bool
+	Routes       RouteSnapshotConfig
+}
+
+// errInstallOrder is returned when Install runs after handlers were registered
+var errInstallOrder = errors.New("sentrykit.Install must be called before registering middleware or routes, or panics in them bypass Sentry")
+
+// Install initializes Sentry for app (see InitForApp) and wires everything in
+// the right order: the middleware as the first handler, so it wraps every
+// other middleware and route; error capture around the app's error handler;
+// a flush on shutdown; and, with ReportRoutes, a route snapshot on listen.
+// Healthcheck paths are skipped by the middleware's defaults. Call it right
+// after fiber.New:
+//
+//	app := fiber.New()
+//	if err := sentrykit.Install(app, sentrykit.Config{DSN: os.Getenv("SENTRY_DSN")}); err != nil {
+//	    log.Fatal(err)
+//	}
+func Install(app *fiber.App, cfg Config, config ...InstallConfig) error {
+	install := InstallConfig{Middleware: DefaultMiddlewareConfig()}
+	if len(config) > 0 {
+		install = config[0]
+	}
+
+	if app.HandlersCount() > 0 {
+		return errInstallOrder
+	}
+	if err := install.Middleware.Validate(); err != nil {
+		return fmt.Errorf("invalid Sentry middleware config: %w", err)
+	}
+
+	if err := InitForApp(app, cfg); err != nil {
+		return err
+	}
+
+	// The app's error handler can't be replaced after fiber.New, so errors
+	// are handled here, inside the request, with the app's handler rendering
+	if install.ErrorHandler.Render == nil {
+		install.ErrorHandler.Render = app.Config().ErrorHandler
+	}
+	handleError := NewErrorHandler(install.ErrorHandler)
+	middleware := New(install.Middleware)
+
+	app.Use(func(c fiber.Ctx) error {
+		if err := middleware(c); err != nil {
+			return handleError(c, err)
+		}
+		return nil
+	})
+
+	// Routes are registered after Install, so they are listed on listen;
+	// with prefork this runs once, in the master
+	if install.ReportRoutes {
+		app.Hooks().OnListen(func(fiber.ListenData) error {
+			ReportRoutes(app, install.Routes)
+			return nil
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package sentrykit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+)
+
+// Carrier keys describing where a job was enqueued, next to the trace headers
+const (
+	jobOriginTransactionKey = "sentrykit-origin-transaction"
+	jobOriginSpanKey        = "sentrykit-origin-span"
+	jobEnqueuedAtKey        = "sentrykit-enqueued-at"
+)
+
+// EnqueueJob runs enqueue inside a "queue.publish" span of the request and
+// writes the trace headers and the enqueuing transaction into carrier
+// before, so enqueue can store carrier with the job. The job's RunJob
+// transaction then joins the request's trace under this span. sentry-go
+// has no span links, so the causal chain is a shared trace plus origin
+// tags.
+//
+//	carrier := map[string]string{}
+//	err := sentrykit.EnqueueJob(c, "emails.welcome", carrier, func() error {
+//	    return queue.Publish(ctx, payload, carrier)
+//	})
+func EnqueueJob(c fiber.Ctx, job string, carrier map[string]string, enqueue func() error) error {
+	ctx := c.UserContext()
+	span := sentry.StartSpan(ctx, "queue.publish", sentry.WithDescription(job))
+	defer span.Finish()
+	span.SetData("messaging.destination.name", job)
+
+	InjectTraceContext(span.Context(), carrier)
+	if transaction := sentry.TransactionFromContext(ctx); transaction != nil {
+		carrier[jobOriginTransactionKey] = transaction.Name
+	}
+	carrier[jobOriginSpanKey] = span.SpanID.String()
+	carrier[jobEnqueuedAtKey] = strconv.FormatInt(now().UnixMilli(), 10)
+
+	err := enqueue()
+	span.Status = spanStatusFromError(err)
+	return err
+}
+
+// RunJob runs fn inside a "queue.process" transaction continuing the trace
+// written into carrier by EnqueueJob. Events and the transaction are tagged
+// with the enqueuing transaction (origin_transaction), and the "job"
+// context records the origin and queue latency. The transaction status is
+// set from the returned error.
+func RunJob(ctx context.Context, job string, carrier map[string]string, fn func(ctx context.Context) error) error {
+	// Each job gets its own hub, so its tags don't leak into the caller's
+	hub := sentry.GetHubFromContext(ctx)
+	if hub == nil {
+		hub = sentry.CurrentHub()
+	}
+	hub = hub.Clone()
+	ctx = sentry.SetHubOnContext(ctx, hub)
+
+	transaction := sentry.StartTransaction(ctx, job,
+		sentry.WithOpName("queue.process"),
+		sentry.WithTransactionSource(sentry.SourceTask),
+		ExtractTrace(carrier),
+	)
+	defer transaction.Finish()
+
+	data := map[string]interface{}{
+		"name":           job,
+		"origin_span_id": carrier[jobOriginSpanKey],
+	}
+	if origin := carrier[jobOriginTransactionKey]; origin != "" {
+		data["origin_transaction"] = origin
+		transaction.SetTag("origin_transaction", origin)
+		hub.Scope().SetTag("origin_transaction", origin)
+	}
+	if enqueuedAt, err := strconv.ParseInt(carrier[jobEnqueuedAtKey], 10, 64); err == nil {
+		latency := since(time.UnixMilli(enqueuedAt))
+		data["enqueued_at"] = time.UnixMilli(enqueuedAt).UTC().Format(time.RFC3339Nano)
+		data["latency_ms"] = milliseconds(latency)
+		transaction.SetData("messaging.message.receive.latency", latency.Milliseconds())
+	}
+	transaction.SetContext("job", data)
+	hub.Scope().SetContext("job", data)
+
+	err := fn(transaction.Context())
+	transaction.Status = spanStatusFromError(err)
+
+	return err
+}
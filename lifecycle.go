@@ -0,0 +1,136 @@
+package sentrykit
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// Reasons passed to OnDropped
+const (
+	// DropReasonLoadShedding: sampled out by Config.LoadShedding
+	DropReasonLoadShedding = "load_shedding"
+
+	// DropReasonProcessor: a registered EventProcessor returned nil
+	DropReasonProcessor = "processor"
+
+	// DropReasonQueueFull: the transport's queue was full
+	DropReasonQueueFull = "queue_full"
+)
+
+// captureHooks are the lifecycle callbacks of a client or middleware
+type captureHooks struct {
+	onCaptured func(eventID sentry.EventID, event *sentry.Event)
+	onDropped  func(reason string, event *sentry.Event)
+}
+
+// newCaptureHooks returns the hooks, or nil when neither is set
+func newCaptureHooks(onCaptured func(sentry.EventID, *sentry.Event), onDropped func(string, *sentry.Event)) *captureHooks {
+	if onCaptured == nil && onDropped == nil {
+		return nil
+	}
+	return &captureHooks{onCaptured: onCaptured, onDropped: onDropped}
+}
+
+// captured calls OnCaptured. A panicking hook is reported as an internal
+// failure and doesn't affect delivery.
+func (h *captureHooks) captured(event *sentry.Event) {
+	if h == nil || h.onCaptured == nil {
+		return
+	}
+	defer recoverHook("OnCaptured")
+	h.onCaptured(event.EventID, event)
+}
+
+// dropped calls OnDropped, recovering panics like captured
+func (h *captureHooks) dropped(reason string, event *sentry.Event) {
+	if h == nil || h.onDropped == nil {
+		return
+	}
+	defer recoverHook("OnDropped")
+	h.onDropped(reason, event)
+}
+
+// recoverHook reports a panicking hook
+func recoverHook(name string) {
+	if r := recover(); r != nil {
+		reportInternal("hook", fmt.Sprintf("%s panicked: %v", name, r))
+	}
+}
+
+// requestEvents maps the IDs of events captured through a request hub to
+// the middleware's hooks until BeforeSend takes them. Events dropped on the
+// way, and check-ins which skip BeforeSend, are forgotten when the request
+// ends.
+var requestEvents sync.Map // sentry.EventID -> *captureHooks
+
+// requestHooksProcessor records events of a request hub with the
+// middleware's hooks
+func requestHooksProcessor(r *requestState, hooks *captureHooks) sentry.EventProcessor {
+	return func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+		// Scope snapshots apply the scope to an event without an ID
+		if event.EventID == "" {
+			return event
+		}
+		requestEvents.Store(event.EventID, hooks)
+		r.trackEvent(event.EventID)
+		return event
+	}
+}
+
+// takeRequestHooks removes and returns the middleware's hooks of an event
+func takeRequestHooks(event *sentry.Event) *captureHooks {
+	hooks, ok := requestEvents.LoadAndDelete(event.EventID)
+	if !ok {
+		return nil
+	}
+	return hooks.(*captureHooks)
+}
+
+// beforeSend sheds error events under load, then runs the processor
+// pipeline, reporting the outcome to the client's and the middleware's hooks.
+// In debug mode the final event is checked for problems.
+func beforeSend(shed *loadShedder, hooks *captureHooks) func(*sentry.Event, *sentry.EventHint) *sentry.Event {
+	return func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+		request := takeRequestHooks(event)
+		applyTimeSource(event)
+
+		if shed.shedError(event) == nil {
+			recordDrop(DropReasonLoadShedding)
+			hooks.dropped(DropReasonLoadShedding, event)
+			request.dropped(DropReasonLoadShedding, event)
+			return nil
+		}
+
+		processed := runProcessors(event, hint)
+		if processed == nil {
+			recordDrop(DropReasonProcessor)
+			hooks.dropped(DropReasonProcessor, event)
+			request.dropped(DropReasonProcessor, event)
+			return nil
+		}
+
+		logEventProblems(processed)
+		checkMarshal(processed)
+		hooks.captured(processed)
+		request.captured(processed)
+		return processed
+	}
+}
+
+// beforeSendTransaction sheds transactions under load. Hooks only report
+// error and message events.
+func beforeSendTransaction(shed *loadShedder) func(*sentry.Event, *sentry.EventHint) *sentry.Event {
+	return func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+		takeRequestHooks(event)
+		applyEventID(event)
+		if shed.shedTransaction(event, hint) == nil {
+			recordDrop(DropReasonLoadShedding)
+			return nil
+		}
+		logEventProblems(event)
+		checkMarshal(event)
+		return event
+	}
+}
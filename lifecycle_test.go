@@ -0,0 +1,71 @@
+package sentrykit
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestRequestHooksStayOutOfEvent(t *testing.T) {
+	transport := &sentry.MockTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:        testDSN,
+		Transport:  transport,
+		BeforeSend: beforeSend(nil, nil),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hub := sentry.CurrentHub()
+	previous := hub.Client()
+	hub.BindClient(client)
+	t.Cleanup(func() { hub.BindClient(previous) })
+
+	captured := make(chan sentry.EventID, 1)
+	app := fiber.New()
+	app.Use(New(MiddlewareConfig{
+		OnCaptured: func(eventID sentry.EventID, event *sentry.Event) {
+			captured <- eventID
+		},
+	}))
+	app.Get("/", func(c fiber.Ctx) error {
+		sentry.GetHubFromContext(c.UserContext()).CaptureMessage("hooks test")
+		return c.SendString("ok")
+	})
+
+	if _, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	var id sentry.EventID
+	select {
+	case id = <-captured:
+	case <-time.After(time.Second):
+		t.Fatal("OnCaptured not called")
+	}
+
+	event := waitForEvent(transport, time.Second, func(event *sentry.Event) bool {
+		return event.Message == "hooks test"
+	})
+	if event == nil {
+		t.Fatal("event not sent")
+	}
+	if event.EventID != id {
+		t.Errorf("OnCaptured got event %s, sent %s", id, event.EventID)
+	}
+	if _, ok := event.Contexts["sentrykit_hooks"]; ok {
+		t.Error("hooks sent in the event contexts")
+	}
+
+	pending := 0
+	requestEvents.Range(func(key, value interface{}) bool {
+		pending++
+		return true
+	})
+	if pending != 0 {
+		t.Errorf("%d request events left after the request ended", pending)
+	}
+}
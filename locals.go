@@ -0,0 +1,43 @@
+package sentrykit
+
+import (
+	"github.com/gofiber/fiber/v3"
+)
+
+// strictLocalsKey marks a request whose kit-owned Locals are cleared by
+// NewErrorHandler, because the middleware returned an error still to be
+// handled
+const strictLocalsKey = "sentry_strict_locals"
+
+// requestLocals are the kit-owned Locals describing a single request
+var requestLocals = []string{"sentry_hub", requestStateKey, capturedEventKey, recoveredPanicKey, handlerTimingsKey}
+
+// resetRequestLocals clears kit-owned Locals left on a reused context, so a
+// hub, captured event or recovered panic of an earlier request on the same
+// connection is never mistaken for this request's
+func resetRequestLocals(c fiber.Ctx) {
+	for _, key := range requestLocals {
+		if c.Locals(key) != nil {
+			c.Locals(key, nil)
+		}
+	}
+}
+
+// clearLocals zeroes all kit-owned Locals at the end of a request in strict
+// mode, so code holding on to the context afterwards (e.g. a goroutine
+// started by the handler) gets the global hub instead of a finished
+// request's hub or, once the context is reused, the next request's
+func clearLocals(c fiber.Ctx) {
+	resetRequestLocals(c)
+	c.Locals(timeoutKey, nil)
+	c.Locals(strictLocalsKey, nil)
+}
+
+// releaseLocals clears the kit-owned Locals when the middleware returns,
+// unless an error is left to NewErrorHandler, which clears them after
+// rendering it
+func releaseLocals(c fiber.Ctx) {
+	if c.Locals(strictLocalsKey) == nil {
+		clearLocals(c)
+	}
+}
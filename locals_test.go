@@ -0,0 +1,92 @@
+package sentrykit
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestKeepAliveRequestsDontShareLocals(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		t.Run(map[bool]string{false: "default", true: "strict"}[strict], func(t *testing.T) {
+			bindTestClient(t)
+
+			var firstHub *sentry.Hub
+			var leaked, kept []string
+
+			app := fiber.New()
+			// Runs before the kit, so it sees what the context carries over
+			app.Use(func(c fiber.Ctx) error {
+				for _, key := range requestLocals {
+					if c.Locals(key) != nil && c.Path() == "/second" {
+						leaked = append(leaked, key)
+					}
+				}
+				err := c.Next()
+				if strict && c.Path() == "/second" {
+					for _, key := range requestLocals {
+						if c.Locals(key) != nil {
+							kept = append(kept, key)
+						}
+					}
+				}
+				return err
+			})
+			app.Use(New(MiddlewareConfig{StrictLocals: strict}))
+			app.Get("/first", func(c fiber.Ctx) error {
+				firstHub = GetHubFromContext(c)
+				return errors.New("first request failed")
+			})
+			app.Get("/second", func(c fiber.Ctx) error {
+				if GetHubFromContext(c) == firstHub {
+					t.Error("second request got the first request's hub")
+				}
+				if id := c.Locals(capturedEventKey); id != nil {
+					t.Errorf("second request sees captured event %v", id)
+				}
+				return c.SendString("ok")
+			})
+
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			go app.Listener(ln, fiber.ListenConfig{DisableStartupMessage: true})
+			t.Cleanup(func() { _ = app.Shutdown() })
+
+			client := &http.Client{Transport: &http.Transport{MaxConnsPerHost: 1}}
+			defer client.CloseIdleConnections()
+
+			var reused bool
+			for _, path := range []string{"/first", "/second"} {
+				trace := &httptrace.ClientTrace{GotConn: func(info httptrace.GotConnInfo) { reused = info.Reused }}
+				req, _ := http.NewRequest(fiber.MethodGet, "http://"+ln.Addr().String()+path, nil)
+				resp, err := client.Do(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				_, _ = io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}
+
+			if !reused {
+				t.Fatal("second request didn't reuse the keep-alive connection")
+			}
+			if firstHub == nil {
+				t.Fatal("first request had no hub")
+			}
+			if len(leaked) > 0 {
+				t.Errorf("second request started with the first's Locals %v", leaked)
+			}
+			if len(kept) > 0 {
+				t.Errorf("strict mode kept Locals %v after the request", kept)
+			}
+		})
+	}
+}
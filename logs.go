@@ -0,0 +1,174 @@
+package sentrykit
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/getsentry/sentry-go/attribute"
+	"github.com/gofiber/fiber/v3"
+)
+
+// LoggerFromContext returns a Sentry structured logger for the request.
+// Entries are tied to the request's trace and carry the route and method as
+// attributes. Requires Config.EnableLogs; otherwise entries are discarded.
+//
+//	sentrykit.LoggerFromContext(c).Info().String("order_id", id).Emit("order placed")
+func LoggerFromContext(c fiber.Ctx) sentry.Logger {
+	hub := activeHub(c)
+	if hub == nil {
+		// Without a client sentry-go returns a no-op logger
+		return sentry.NewLogger(c.UserContext())
+	}
+	logger := sentry.NewLogger(sentry.SetHubOnContext(c.UserContext(), hub))
+	logger.SetAttributes(
+		attribute.String("http.route", c.Route().Path),
+		attribute.String("http.request.method", strings.Clone(c.Method())),
+	)
+	return logger
+}
+
+// LogHandlerConfig configures the slog handler exporting to Sentry Logs
+type LogHandlerConfig struct {
+	// Level is the minimum level exported (default: slog.LevelInfo)
+	Level slog.Leveler
+
+	// Next also receives every record, e.g. a JSON handler writing to
+	// stdout, so Sentry can be added alongside existing log output (optional)
+	Next slog.Handler
+}
+
+// logHandler exports slog records as Sentry Log entries
+type logHandler struct {
+	cfg    LogHandlerConfig
+	attrs  []slog.Attr
+	prefix string
+}
+
+// NewLogHandler returns an slog.Handler exporting records as Sentry Log
+// entries (requires Config.EnableLogs). Records logged with a request
+// context, e.g. slog.InfoContext(c.UserContext(), ...), are tied to the
+// request's trace. Attributes in groups are flattened to dotted keys.
+func NewLogHandler(config ...LogHandlerConfig) slog.Handler {
+	var cfg LogHandlerConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Level == nil {
+		cfg.Level = slog.LevelInfo
+	}
+	return &logHandler{cfg: cfg}
+}
+
+// Enabled reports whether records at level are exported or passed on
+func (h *logHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if level >= h.cfg.Level.Level() {
+		return true
+	}
+	return h.cfg.Next != nil && h.cfg.Next.Enabled(ctx, level)
+}
+
+// Handle exports the record and passes it on to Next
+func (h *logHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level >= h.cfg.Level.Level() {
+		entry := logEntry(sentry.NewLogger(ctx), record.Level)
+		for _, attr := range h.attrs {
+			setLogAttr(entry, "", attr)
+		}
+		record.Attrs(func(attr slog.Attr) bool {
+			setLogAttr(entry, h.prefix, attr)
+			return true
+		})
+		// Emit formats the message; escape verbs so it is sent verbatim
+		entry.Emit(strings.ReplaceAll(record.Message, "%", "%%"))
+	}
+
+	if h.cfg.Next != nil && h.cfg.Next.Enabled(ctx, record.Level) {
+		return h.cfg.Next.Handle(ctx, record)
+	}
+	return nil
+}
+
+// WithAttrs returns a handler adding attrs to every record
+func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, attr := range attrs {
+		attr.Key = h.prefix + attr.Key
+		clone.attrs = append(clone.attrs, attr)
+	}
+	if h.cfg.Next != nil {
+		clone.cfg.Next = h.cfg.Next.WithAttrs(attrs)
+	}
+	return &clone
+}
+
+// WithGroup returns a handler qualifying later attributes with name
+func (h *logHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.prefix = h.prefix + name + "."
+	if h.cfg.Next != nil {
+		clone.cfg.Next = h.cfg.Next.WithGroup(name)
+	}
+	return &clone
+}
+
+// logEntry starts an entry at the Sentry level matching an slog level.
+// Errors and above map to error; fatal would exit the process.
+func logEntry(logger sentry.Logger, level slog.Level) sentry.LogEntry {
+	switch {
+	case level >= slog.LevelError:
+		return logger.Error()
+	case level >= slog.LevelWarn:
+		return logger.Warn()
+	case level >= slog.LevelInfo:
+		return logger.Info()
+	default:
+		return logger.Debug()
+	}
+}
+
+// setLogAttr adds an slog attribute to the entry, flattening groups
+func setLogAttr(entry sentry.LogEntry, prefix string, attr slog.Attr) {
+	value := attr.Value.Resolve()
+	if attr.Key == "" && value.Kind() != slog.KindGroup {
+		return
+	}
+	key := prefix + attr.Key
+
+	switch value.Kind() {
+	case slog.KindGroup:
+		groupPrefix := prefix
+		if attr.Key != "" {
+			groupPrefix = key + "."
+		}
+		for _, member := range value.Group() {
+			setLogAttr(entry, groupPrefix, member)
+		}
+	case slog.KindString:
+		entry.String(key, value.String())
+	case slog.KindInt64:
+		entry.Int64(key, value.Int64())
+	case slog.KindUint64:
+		if u := value.Uint64(); u <= math.MaxInt64 {
+			entry.Int64(key, int64(u))
+		} else {
+			entry.String(key, strconv.FormatUint(u, 10))
+		}
+	case slog.KindFloat64:
+		entry.Float64(key, value.Float64())
+	case slog.KindBool:
+		entry.Bool(key, value.Bool())
+	case slog.KindTime:
+		entry.String(key, value.Time().Format(time.RFC3339Nano))
+	default:
+		entry.String(key, value.String())
+	}
+}
@@ -0,0 +1,128 @@
+package sentrykit
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// MemoryWatchdogConfig configures the memory watchdog
+type MemoryWatchdogConfig struct {
+	// Fraction of the memory limit at which heap usage is reported
+	// (default: 0.8)
+	Fraction float64
+
+	// Limit is the memory limit in bytes (default: the container's cgroup
+	// limit, else GOMEMLIMIT)
+	Limit uint64
+
+	// Interval between heap usage checks (default: 10s)
+	Interval time.Duration
+
+	// Cooldown is the minimum time between two reports (default: 10m)
+	Cooldown time.Duration
+}
+
+// StartMemoryWatchdog captures a warning with a pprof heap profile
+// ("heap.pprof") attached when heap usage crosses Fraction of the memory
+// limit, leaving a trace of what filled memory before an OOM kill. Without
+// a configured, cgroup or GOMEMLIMIT limit it does nothing. Call the
+// returned function to stop.
+func StartMemoryWatchdog(config ...MemoryWatchdogConfig) (stop func()) {
+	var cfg MemoryWatchdogConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Fraction <= 0 || cfg.Fraction > 1 {
+		cfg.Fraction = 0.8
+	}
+	if cfg.Limit == 0 {
+		cfg.Limit = memoryLimit()
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 10 * time.Second
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 10 * time.Minute
+	}
+
+	if cfg.Limit == 0 {
+		return func() {}
+	}
+
+	limiter := &cooldown{interval: cfg.Cooldown}
+	threshold := uint64(float64(cfg.Limit) * cfg.Fraction)
+
+	return startTicker(cfg.Interval, func() {
+		var mem runtime.MemStats
+		runtime.ReadMemStats(&mem)
+
+		if mem.HeapAlloc < threshold || !limiter.allow() {
+			return
+		}
+
+		usage := float64(mem.HeapAlloc) / float64(cfg.Limit)
+		captureDiagnostic(sentry.CurrentHub(), diagnostic{
+			message:     fmt.Sprintf("Heap usage at %.0f%% of memory limit", usage*100),
+			fingerprint: []string{"memory", "heap_limit"},
+			context: map[string]interface{}{
+				"heap_alloc_bytes": mem.HeapAlloc,
+				"heap_inuse_bytes": mem.HeapInuse,
+				"sys_bytes":        mem.Sys,
+				"limit_bytes":      cfg.Limit,
+				"usage":            usage,
+				"fraction":         cfg.Fraction,
+				"num_gc":           mem.NumGC,
+				"goroutines":       runtime.NumGoroutine(),
+			},
+			attachments: []*sentry.Attachment{heapProfile()},
+		})
+	})
+}
+
+// memoryLimit returns the container memory limit from cgroup v2 or v1,
+// falling back to GOMEMLIMIT, or 0 when unlimited
+func memoryLimit() uint64 {
+	paths := []string{
+		"/sys/fs/cgroup/memory.max",                   // cgroup v2
+		"/sys/fs/cgroup/memory/memory.limit_in_bytes", // cgroup v1
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		limit, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+		// "max" (v2) or a huge page-rounded value (v1) mean unlimited
+		if err == nil && limit < math.MaxInt64/2 {
+			return limit
+		}
+	}
+
+	if limit := debug.SetMemoryLimit(-1); limit != math.MaxInt64 {
+		return uint64(limit)
+	}
+	return 0
+}
+
+// heapProfile returns a pprof heap profile as an attachment
+func heapProfile() *sentry.Attachment {
+	var buf bytes.Buffer
+	if profile := pprof.Lookup("heap"); profile != nil {
+		_ = profile.WriteTo(&buf, 0)
+	}
+	return &sentry.Attachment{
+		Filename:    "heap.pprof",
+		ContentType: "application/octet-stream",
+		Payload:     buf.Bytes(),
+	}
+}
@@ -1,23 +1,188 @@
 package sentrykit
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
 	"time"
 
 	"github.com/getsentry/sentry-go"
 	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/session"
 )
 
 // MiddlewareConfig holds configuration for Fiber middleware
 type MiddlewareConfig struct {
 	// Repanic configures whether Sentry should repanic after recovery
 	Repanic bool
-	
+
 	// WaitForDelivery configures whether to block/wait until events are sent
 	WaitForDelivery bool
-	
+
 	// Timeout for event delivery
 	Timeout time.Duration
+
+	// AuditScopeIsolation reports a diagnostic event when data (tags,
+	// contexts, extras, user) was added to the global scope since Init or a
+	// hub from a previous request is still attached to a reused context.
+	// Only runs when Init was called with Config.Debug.
+	AuditScopeIsolation bool
+
+	// TransactionOp overrides the request transaction operation (default: "http.server")
+	TransactionOp string
+
+	// TransactionNameFormatter names request transactions from the method and
+	// matched route template (default: "METHOD /route/:param")
+	TransactionNameFormatter func(method, route string) string
+
+	// SharedHub starts each request on a plain clone of the base hub and
+	// only adds the request context, user and tags when a helper or capture
+	// needs them, which greatly reduces overhead for high-throughput,
+	// low-error services.
+	SharedHub bool
+
+	// StrictLocals zeroes the kit-owned Locals (request hub, state, captured
+	// event ID) when the request ends: when the middleware returns or, for
+	// a returned error, after NewErrorHandler rendered it. Code using the
+	// context after the request then gets the global hub instead of a
+	// finished request's hub, or the next one's on a reused keep-alive
+	// context. Fiber and fasthttp only.
+	StrictLocals bool
+
+	// HeaderAllowlist switches header capture to allowlist mode: only the
+	// listed headers are ever sent, instead of all but the sensitive ones
+	HeaderAllowlist []string
+
+	// Cookies lists cookies recorded in the "cookies" context, hashed or
+	// truncated so raw values (e.g. experiment buckets) are never stored
+	Cookies []CookieMapping
+
+	// Ownership maps route prefixes to the owning team, e.g.
+	// {"/api/billing": "payments", "/api/search": "discovery"}. Events get
+	// the team of the longest matching prefix as the OwnershipTag tag, for
+	// Sentry ownership rules and alert routing.
+	Ownership map[string]string
+
+	// OwnershipTag is the tag set from Ownership (default: "team")
+	OwnershipTag string
+
+	// HeaderTags maps request headers to tag names, e.g.
+	// {"X-App-Version": "client_version", "X-Platform": "platform"}
+	HeaderTags map[string]string
+
+	// ClientVersionHeader names the request header carrying the caller's
+	// release (e.g. "X-Client-Version"). Events are tagged client_release,
+	// server_release and, when both are versions, version_skew: the number
+	// of minor versions the client is behind the server.
+	ClientVersionHeader string
+
+	// MaxVersionSkew captures a distinct "version skew" warning when the
+	// client release is more than this many minor versions away from the
+	// server release, or on another major version, to catch stale clients
+	// hitting new APIs. Each release pair is reported at most every 10
+	// minutes (0 = disabled; requires ClientVersionHeader).
+	MaxVersionSkew int
+
+	// HealthcheckPaths are liveness/readiness paths skipped entirely: no
+	// hub, transaction or capture (default: DefaultHealthcheckPaths, the
+	// endpoints of Fiber's healthcheck middleware)
+	HealthcheckPaths []string
+
+	// TraceHealthchecks instruments healthcheck paths like any other route
+	TraceHealthchecks bool
+
+	// ParamDenylist lists route param name fragments (e.g. "token") whose
+	// values are filtered from the request context; matched params are
+	// otherwise included (default: DefaultParamDenylist)
+	ParamDenylist []string
+
+	// MaxBreadcrumbs caps the breadcrumbs sent with events from a request
+	// hub, evicting the oldest first and recording the number dropped in the
+	// "truncated_breadcrumbs" extra (0 = client limit only)
+	MaxBreadcrumbs int
+
+	// LocalsExtras lists c.Locals keys (e.g. "order_id") copied into event
+	// extras, so context set by other middleware reaches Sentry. Values are
+	// read when the request hub is created and again after the handler chain.
+	// Fiber only.
+	LocalsExtras []string
+
+	// SessionStore tags events with the hashed session ID, whether the
+	// session is fresh and its age. Use the same store as the application.
+	// Fiber only.
+	SessionStore *session.Store
+
+	// SessionCreatedKey is the session key where the application stores the
+	// session creation time (time.Time or unix seconds), used for session age
+	SessionCreatedKey string
+
+	// TenantExtractor returns the tenant and user of a request (e.g. from a
+	// header or auth locals). The result tags events (tenant_id,
+	// tenant_tier, user) and is passed to Config.TracesSampler. Fiber only.
+	TenantExtractor func(c fiber.Ctx) TenantInfo
+
+	// Client reports requests through a named client created by NewClient
+	// instead of the global client, e.g. for one route group
+	Client *Client
+
+	// DebugHeader and DebugToken enable on-demand full telemetry: a request
+	// carrying DebugHeader with the DebugToken value gets a sampled
+	// transaction and has 4xx errors captured too. The header is never
+	// captured. Requires tracing to be enabled for the forced transaction.
+	DebugHeader string
+	DebugToken  string
+
+	// PanicHandler writes the response for a recovered panic, given the ID
+	// of the captured event, e.g. ErrorPage.RenderPanic (default: a plain
+	// 500). Not called when Repanic is set. Fiber only.
+	PanicHandler func(c fiber.Ctx, eventID *sentry.EventID) error
+
+	// CaptureErrorResponses captures 5xx responses whose error never
+	// reached the middleware, e.g. because an inner middleware rendered it
+	// with fiber.DefaultErrorHandler and returned nil. The response body,
+	// which holds the error message for the default handler, becomes the
+	// event message. Fiber only.
+	CaptureErrorResponses bool
+
+	// ReplayBundle attaches a sanitized "request-replay.json" to error
+	// events captured through the request hub: method, URL, captured
+	// headers (secret ones present as "[Filtered]") and the JSON, form or
+	// plain text body with denylisted fields redacted, enough to reproduce
+	// the request. net/http requests have no body in the bundle.
+	ReplayBundle bool
+
+	// CurlCommand adds a ready-to-run curl command reproducing the request
+	// to error events, as the "reproduce" context (or a "request.curl.sh"
+	// attachment when long), sanitized like the replay bundle
+	CurlCommand bool
+
+	// HARAttachment attaches a HAR 1.2 document ("request.har") with the
+	// request/response pair to error events: headers, bodies (sanitized and
+	// truncated like the replay bundle) and the handler time, importable in
+	// browser devtools
+	HARAttachment bool
+
+	// ReplayRedactFields lists field name fragments whose values are
+	// redacted from replayed query strings and bodies (matched
+	// case-insensitively; default: DefaultParamDenylist)
+	ReplayRedactFields []string
+
+	// ReplayMaxBody caps the replayed body in bytes (default: 64 KiB)
+	ReplayMaxBody int
+
+	// OnCaptured and OnDropped are called for events captured through the
+	// request hub, like Config.OnCaptured and Config.OnDropped, e.g. for
+	// per-route-group metrics (queue_full drops are only reported to Config)
+	OnCaptured func(eventID sentry.EventID, event *sentry.Event)
+	OnDropped  func(reason string, event *sentry.Event)
+
+	// DisableRouteGrouping keeps sentry-go's default grouping for returned
+	// fiber.Errors. By default the route template is prepended to their
+	// fingerprint, so the same error on different routes forms separate issues.
+	DisableRouteGrouping bool
 }
 
 // DefaultMiddlewareConfig returns default middleware configuration
@@ -29,57 +194,143 @@ func DefaultMiddlewareConfig() MiddlewareConfig {
 	}
 }
 
-// New creates a new Sentry middleware for Fiber
-func New(config ...MiddlewareConfig) fiber.Handler {
+// Validate reports invalid or contradictory options
+func (cfg MiddlewareConfig) Validate() error {
+	var errs []error
+
+	if cfg.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("Timeout must not be negative, got %s", cfg.Timeout))
+	}
+	if cfg.WaitForDelivery && cfg.Timeout <= 0 {
+		errs = append(errs, errors.New("WaitForDelivery requires a positive Timeout; start from DefaultMiddlewareConfig()"))
+	}
+	if (cfg.DebugHeader == "") != (cfg.DebugToken == "") {
+		errs = append(errs, errors.New("DebugHeader and DebugToken must be set together"))
+	}
+	if cfg.MaxBreadcrumbs < 0 {
+		errs = append(errs, fmt.Errorf("MaxBreadcrumbs must not be negative, got %d", cfg.MaxBreadcrumbs))
+	}
+	for _, mapping := range cfg.Cookies {
+		if err := mapping.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if cfg.MaxVersionSkew < 0 {
+		errs = append(errs, fmt.Errorf("MaxVersionSkew must not be negative, got %d", cfg.MaxVersionSkew))
+	}
+	if cfg.MaxVersionSkew > 0 && cfg.ClientVersionHeader == "" {
+		errs = append(errs, errors.New("MaxVersionSkew has no effect without ClientVersionHeader"))
+	}
+	if cfg.ReplayMaxBody < 0 {
+		errs = append(errs, fmt.Errorf("ReplayMaxBody must not be negative, got %d", cfg.ReplayMaxBody))
+	}
+	if cfg.SessionCreatedKey != "" && cfg.SessionStore == nil {
+		errs = append(errs, errors.New("SessionCreatedKey has no effect without SessionStore"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// requestStateKey holds the request state, used to create the hub in
+// shared-hub mode and to record deadlines set by Timeout
+const requestStateKey = "sentry_request_state"
+
+// middlewareConfig resolves the optional adapter config, panicking on
+// invalid options so misconfiguration surfaces at startup
+func middlewareConfig(config ...MiddlewareConfig) MiddlewareConfig {
 	cfg := DefaultMiddlewareConfig()
 	if len(config) > 0 {
 		cfg = config[0]
 	}
+	if err := cfg.Validate(); err != nil {
+		panic(fmt.Sprintf("sentrykit: invalid middleware config: %v", err))
+	}
+	return cfg
+}
 
-	return func(c fiber.Ctx) error {
-		// Create a new hub for this request
-		hub := sentry.CurrentHub().Clone()
-
-		// Add request context
-		hub.Scope().SetContext("request", map[string]interface{}{
-			"url":          c.OriginalURL(),
-			"method":       c.Method(),
-			"query_string": string(c.Request().URI().QueryString()),
-			"headers":      extractHeaders(c),
-			"ip":           c.IP(),
-			"user_agent":   c.Get("User-Agent"),
-		})
+// New creates a new Sentry middleware for Fiber.
+// It panics if the config is invalid (see MiddlewareConfig.Validate).
+func New(config ...MiddlewareConfig) fiber.Handler {
+	cfg := middlewareConfig(config...)
+
+	captureHeader := headerFilter(cfg)
+	isHealthcheck := healthcheckFilter(cfg)
 
-		// Add custom tags
-		hub.Scope().SetTag("path", c.Path())
-		hub.Scope().SetTag("method", c.Method())
+	return func(c fiber.Ctx) error {
+		// Probes are frequent and uninteresting; skip hub setup and tracing
+		if isHealthcheck(c.Path()) {
+			return c.Next()
+		}
 
-		// Extract and set user info if available
-		if userID := c.Locals("user_id"); userID != nil {
-			hub.Scope().SetUser(sentry.User{
-				ID: fmt.Sprintf("%v", userID),
+		if cfg.AuditScopeIsolation && debugEnabled() && (c.Locals("sentry_hub") != nil || c.Locals(requestStateKey) != nil) {
+			reportScopeLeak("stale_request_hub", map[string]interface{}{
+				"detected_on": strings.Clone(c.Path()),
 			})
 		}
+		resetRequestLocals(c)
+		if !clientBound(cfg) {
+			return serveUnreported(c, cfg)
+		}
+		if cfg.StrictLocals {
+			// Deferred first, so it runs after the request is finished
+			defer releaseLocals(c)
+		}
 
-		// Extract tenant ID from params if available
-		if tenantID := c.Params("tenantId"); tenantID != "" {
-			hub.Scope().SetTag("tenant_id", tenantID)
+		ctx := c.UserContext()
+		var tenant TenantInfo
+		if cfg.TenantExtractor != nil {
+			tenant = cfg.TenantExtractor(c)
+			ctx = withTenant(ctx, tenant)
 		}
 
-		// Store hub in context for later use
-		c.Locals("sentry_hub", hub)
+		state := startRequest(ctx, cfg, fiberRequestInfo(c, captureHeader), func(hub *sentry.Hub) {
+			// Extract and set user info if available
+			if userID := c.Locals("user_id"); userID != nil {
+				hub.Scope().SetUser(sentry.User{
+					ID: fmt.Sprintf("%v", userID),
+				})
+			}
+
+			// Extract tenant ID from params if available
+			if tenantID := c.Params("tenantId"); tenantID != "" {
+				hub.Scope().SetTag("tenant_id", strings.Clone(tenantID))
+			}
+
+			setTenant(hub, tenant)
+
+			// Tag session info if a store is configured
+			if cfg.SessionStore != nil {
+				setSessionContext(c, hub, cfg.SessionStore, cfg.SessionCreatedKey)
+			}
+
+			setLocalsExtras(c, hub, cfg.LocalsExtras)
+		})
+		defer state.end()
+
+		// Store hub in context for later use; in shared-hub mode it is
+		// created on first use by GetHubFromContext
+		c.Locals(requestStateKey, state)
+		if !cfg.SharedHub {
+			c.Locals("sentry_hub", state.requestHub())
+		}
+		c.SetUserContext(state.context())
 
 		// Recover from panics
 		defer func() {
 			if err := recover(); err != nil {
-				hub.Recover(err)
-				
-				if cfg.WaitForDelivery {
-					hub.Flush(cfg.Timeout)
+				state.setRouteParams(routeParams(c, cfg.ParamDenylist))
+				if hub := state.createdHub(); hub != nil {
+					setLocalsExtras(c, hub, cfg.LocalsExtras)
 				}
-				
-				if cfg.Repanic {
-					panic(err)
+				setHandlerTimings(c, state.requestHub())
+				eventID := state.recoverPanic(c.UserContext(), err)
+				if eventID != nil {
+					c.Locals(capturedEventKey, eventID)
+				}
+				if cfg.PanicHandler != nil {
+					_ = cfg.PanicHandler(c, eventID)
+				} else {
+					_ = c.SendStatus(fiber.StatusInternalServerError)
 				}
 			}
 		}()
@@ -87,45 +338,168 @@ func New(config ...MiddlewareConfig) fiber.Handler {
 		// Process request
 		err := c.Next()
 
-		// Capture errors (5xx only)
+		// Resolve the response status; returned errors are rendered later by the error handler
+		code := c.Response().StatusCode()
 		if err != nil {
-			code := fiber.StatusInternalServerError
-			if e, ok := err.(*fiber.Error); ok {
-				code = e.Code
-			}
+			code = statusFromError(err)
+		}
 
-			// Only capture server errors (5xx)
-			if code >= 500 {
-				hub.CaptureException(err)
-				
-				// Add error context
-				hub.Scope().SetContext("error_details", map[string]interface{}{
-					"error":      err.Error(),
-					"path":       c.Path(),
-					"method":     c.Method(),
-					"status":     code,
-					"ip":         c.IP(),
-					"user_agent": c.Get("User-Agent"),
-				})
-			}
+		// Route params are only resolved once a route matched
+		state.setRouteParams(routeParams(c, cfg.ParamDenylist))
+
+		// Refresh extras with locals set by later middleware and handlers
+		if hub := state.createdHub(); hub != nil {
+			setLocalsExtras(c, hub, cfg.LocalsExtras)
+		}
+
+		// Attach the handler timing breakdown to server errors and timeouts
+		if err != nil && (code >= fiber.StatusInternalServerError || isTimeoutError(err)) {
+			setHandlerTimings(c, state.requestHub())
+		}
+
+		state.setDeadline(fiberDeadline(c))
+
+		state.errorCaptured = c.Locals(recoveredPanicKey) != nil || c.Locals(capturedEventKey) != nil
+
+		// Errors handled further down the chain only show in the response
+		captureErr := err
+		if err == nil && cfg.CaptureErrorResponses && code >= fiber.StatusInternalServerError &&
+			c.Locals(capturedEventKey) == nil {
+			captureErr = responseError(c, code)
+			state.requestHub().Scope().SetTag("error_source", "response")
 		}
 
-		// Flush events if configured
-		if cfg.WaitForDelivery {
-			hub.Flush(cfg.Timeout)
+		state.groupByRoute = !cfg.DisableRouteGrouping && isFiberError(captureErr)
+		state.handler = handlerName(c.Route())
+		if eventID := state.finish(c.UserContext(), c.Route().Path, code, captureErr); eventID != nil {
+			c.Locals(capturedEventKey, eventID)
 		}
 
+		if cfg.StrictLocals && err != nil {
+			c.Locals(strictLocalsKey, true)
+		}
 		return err
 	}
 }
 
-// extractHeaders extracts HTTP headers and filters sensitive ones
-func extractHeaders(c fiber.Ctx) map[string]string {
+// handlerName returns the function name of the route's last handler, or ""
+func handlerName(route *fiber.Route) string {
+	if len(route.Handlers) == 0 {
+		return ""
+	}
+	handler := route.Handlers[len(route.Handlers)-1]
+	if fn := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return ""
+}
+
+// setTenant tags the hub with the extracted tenant
+func setTenant(hub *sentry.Hub, tenant TenantInfo) {
+	if tenant.ID != "" {
+		hub.Scope().SetTag("tenant_id", tenant.ID)
+	}
+	if tenant.Tier != "" {
+		hub.Scope().SetTag("tenant_tier", tenant.Tier)
+	}
+	if tenant.UserID != "" {
+		hub.Scope().SetUser(sentry.User{ID: tenant.UserID})
+	}
+}
+
+// routeParams returns the matched route params, scrubbed by the denylist.
+// Values are copied since Fiber reuses the underlying buffers.
+func routeParams(c fiber.Ctx, denylist []string) map[string]string {
+	names := c.Route().Params
+	if len(names) == 0 {
+		return nil
+	}
+	if denylist == nil {
+		denylist = DefaultParamDenylist
+	}
+
+	params := make(map[string]string, len(names))
+	for _, name := range names {
+		params[name] = strings.Clone(c.Params(name))
+	}
+	return scrubParams(params, denylist)
+}
+
+// setLocalsExtras copies the selected Locals values into event extras
+func setLocalsExtras(c fiber.Ctx, hub *sentry.Hub, keys []string) {
+	for _, key := range keys {
+		if value := c.Locals(key); value != nil {
+			hub.Scope().SetExtra(key, value)
+		}
+	}
+}
+
+// statusFromError returns the response status Fiber renders for an error
+func statusFromError(err error) int {
+	if e, ok := err.(*fiber.Error); ok {
+		return e.Code
+	}
+	return fiber.StatusInternalServerError
+}
+
+// maxResponseErrorMessage caps the response body used as an error message
+const maxResponseErrorMessage = 512
+
+// responseError rebuilds the error of an already rendered error response
+// from its status and body
+func responseError(c fiber.Ctx, code int) error {
+	message := strings.TrimSpace(string(c.Response().Body()))
+	if len(message) > maxResponseErrorMessage {
+		message = message[:maxResponseErrorMessage]
+	}
+	if message == "" {
+		message = http.StatusText(code)
+	}
+	return fiber.NewError(code, message)
+}
+
+// isFiberError reports whether err is a fiber.Error returned by a handler
+func isFiberError(err error) bool {
+	var e *fiber.Error
+	return errors.As(err, &e)
+}
+
+// fiberRequestInfo describes a Fiber request for the shared core
+func fiberRequestInfo(c fiber.Ctx, captureHeader func(key string) bool) requestInfo {
+	// Fiber's strings point into fasthttp buffers that are reused for the
+	// next request on the connection, while events are sent later from the
+	// transport; copy everything that can end up in an event
+	return requestInfo{
+		URL:         strings.Clone(c.OriginalURL()),
+		Method:      strings.Clone(c.Method()),
+		Path:        strings.Clone(c.Path()),
+		Query:       string(c.Request().URI().QueryString()),
+		Headers:     extractHeaders(c, captureHeader),
+		IP:          strings.Clone(c.IP()),
+		UserAgent:   strings.Clone(c.Get("User-Agent")),
+		SentryTrace: strings.Clone(c.Get(sentry.SentryTraceHeader)),
+		Baggage:     strings.Clone(c.Get(sentry.SentryBaggageHeader)),
+		Header: func(name string) string {
+			return strings.Clone(c.Get(name))
+		},
+		Cookie: func(name string) string {
+			return c.Cookies(name)
+		},
+		BaseURL: c.BaseURL(),
+		Body:    c.Body,
+		Response: func() responseInfo {
+			return fasthttpResponseInfo(c.Response())
+		},
+	}
+}
+
+// extractHeaders extracts HTTP headers allowed by the capture filter
+func extractHeaders(c fiber.Ctx, captureHeader func(key string) bool) map[string]string {
 	headers := make(map[string]string)
 	c.Request().Header.VisitAll(func(key, value []byte) {
 		keyStr := string(key)
-		// Skip sensitive headers
-		if keyStr != "Authorization" && keyStr != "Cookie" && keyStr != "X-Api-Key" {
+		// Skip sensitive or non-allowlisted headers
+		if captureHeader(keyStr) {
 			headers[keyStr] = string(value)
 		}
 	})
@@ -137,36 +511,103 @@ func GetHubFromContext(c fiber.Ctx) *sentry.Hub {
 	if hub, ok := c.Locals("sentry_hub").(*sentry.Hub); ok {
 		return hub
 	}
+	if state, ok := c.Locals(requestStateKey).(*requestState); ok {
+		hub := state.requestHub()
+		c.Locals("sentry_hub", hub)
+		return hub
+	}
 	return sentry.CurrentHub()
 }
 
+// WithChildHub runs fn with a clone of the request hub for a sub-operation,
+// e.g. one item of a batch endpoint. Tags, contexts and breadcrumbs set on
+// it only reach events captured through it, not the request scope. While fn
+// runs, GetHubFromContext and the context helpers return the child hub.
+//
+//	for _, item := range items {
+//		sentrykit.WithChildHub(c, func(hub *sentry.Hub) {
+//			hub.Scope().SetTag("item_id", item.ID)
+//			if err := process(item); err != nil {
+//				hub.CaptureException(err)
+//			}
+//		})
+//	}
+func WithChildHub(c fiber.Ctx, fn func(hub *sentry.Hub)) {
+	parent := activeHub(c)
+	if parent == nil {
+		// Keep fn's scope changes off the unbound global scope
+		fn(sentry.NewHub(nil, sentry.NewScope()))
+		return
+	}
+	previous := c.Locals("sentry_hub")
+	ctx := c.UserContext()
+
+	child := parent.Clone()
+	c.Locals("sentry_hub", child)
+	c.SetUserContext(sentry.SetHubOnContext(ctx, child))
+	defer func() {
+		c.Locals("sentry_hub", previous)
+		c.SetUserContext(ctx)
+	}()
+
+	fn(child)
+}
+
 // CaptureExceptionFromContext captures an exception using the hub from context
 func CaptureExceptionFromContext(c fiber.Ctx, err error) *sentry.EventID {
-	hub := GetHubFromContext(c)
+	hub := activeHub(c)
+	if hub == nil {
+		return nil
+	}
 	return hub.CaptureException(err)
 }
 
-// CaptureMessageFromContext captures a message using the hub from context
+// CaptureMessageFromContext captures a message using the hub from context.
+// The level only applies to this message, not to the request scope.
 func CaptureMessageFromContext(c fiber.Ctx, message string, level sentry.Level) *sentry.EventID {
-	hub := GetHubFromContext(c)
-	hub.Scope().SetLevel(level)
-	return hub.CaptureMessage(message)
+	hub := activeHub(c)
+	if hub == nil {
+		return nil
+	}
+	var eventID *sentry.EventID
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetLevel(level)
+		eventID = hub.CaptureMessage(message)
+	})
+	return eventID
+}
+
+// CaptureWithScope captures an exception in an isolated scope derived from the
+// request scope. Changes made by fn apply only to this event and don't leak
+// onto the request hub.
+func CaptureWithScope(c fiber.Ctx, fn func(scope *sentry.Scope), err error) *sentry.EventID {
+	hub := activeHub(c)
+	if hub == nil {
+		return nil
+	}
+	var eventID *sentry.EventID
+	hub.WithScope(func(scope *sentry.Scope) {
+		fn(scope)
+		eventID = hub.CaptureException(err)
+	})
+	return eventID
 }
 
 // AddBreadcrumbFromContext adds a breadcrumb using the hub from context
-func AddBreadcrumbFromContext(c fiber.Ctx, message, category string, data map[string]interface{}) {
-	hub := GetHubFromContext(c)
-	hub.AddBreadcrumb(&sentry.Breadcrumb{
-		Message:  message,
-		Category: category,
-		Data:     data,
-		Level:    sentry.LevelInfo,
-	}, nil)
+func AddBreadcrumbFromContext(c fiber.Ctx, message, category string, data map[string]interface{}, options ...BreadcrumbOptions) {
+	hub := activeHub(c)
+	if hub == nil {
+		return
+	}
+	hub.AddBreadcrumb(newBreadcrumb(message, category, data, options), nil)
 }
 
 // SetUserFromContext sets user information using the hub from context
 func SetUserFromContext(c fiber.Ctx, userID, email, username string) {
-	hub := GetHubFromContext(c)
+	hub := activeHub(c)
+	if hub == nil {
+		return
+	}
 	hub.Scope().SetUser(sentry.User{
 		ID:       userID,
 		Email:    email,
@@ -176,12 +617,45 @@ func SetUserFromContext(c fiber.Ctx, userID, email, username string) {
 
 // SetTagFromContext sets a tag using the hub from context
 func SetTagFromContext(c fiber.Ctx, key, value string) {
-	hub := GetHubFromContext(c)
+	hub := activeHub(c)
+	if hub == nil {
+		return
+	}
 	hub.Scope().SetTag(key, value)
 }
 
 // SetContextFromContext sets context data using the hub from context
 func SetContextFromContext(c fiber.Ctx, key string, data map[string]interface{}) {
-	hub := GetHubFromContext(c)
+	hub := activeHub(c)
+	if hub == nil {
+		return
+	}
 	hub.Scope().SetContext(key, data)
 }
+
+// SetFingerprintFromContext sets the grouping fingerprint using the hub from context
+func SetFingerprintFromContext(c fiber.Ctx, fingerprint []string) {
+	hub := activeHub(c)
+	if hub == nil {
+		return
+	}
+	hub.Scope().SetFingerprint(fingerprint)
+}
+
+// SetLevelFromContext sets the event level using the hub from context
+func SetLevelFromContext(c fiber.Ctx, level sentry.Level) {
+	hub := activeHub(c)
+	if hub == nil {
+		return
+	}
+	hub.Scope().SetLevel(level)
+}
+
+// SetExtraFromContext sets an extra value using the hub from context
+func SetExtraFromContext(c fiber.Ctx, key string, value interface{}) {
+	hub := activeHub(c)
+	if hub == nil {
+		return
+	}
+	hub.Scope().SetExtra(key, value)
+}
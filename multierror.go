@@ -0,0 +1,132 @@
+package sentrykit
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+)
+
+// MultiErrorConfig configures CaptureMultiError
+type MultiErrorConfig struct {
+	// Operation names the parallel work, e.g. "checkout.fetch" (optional)
+	Operation string
+
+	// Separate captures each error as its own event, linked by a shared
+	// "error_group" tag, instead of one event holding all of them
+	Separate bool
+}
+
+// multiError groups the failures of parallel work. sentry-go reports an
+// error with Unwrap() []error as an exception group, one exception entry
+// per failure.
+type multiError struct {
+	operation string
+	errs      []error
+	total     int
+}
+
+// Error summarizes the failures with the first one
+func (e *multiError) Error() string {
+	summary := fmt.Sprintf("%d of %d parallel operations failed", len(e.errs), e.total)
+	if e.operation != "" {
+		summary = e.operation + ": " + summary
+	}
+	return summary + ": " + e.errs[0].Error()
+}
+
+// Unwrap returns the failures
+func (e *multiError) Unwrap() []error {
+	return e.errs
+}
+
+// CaptureMultiError captures the failures of parallel work (errgroup,
+// fan-out handlers) on the request hub as one event with an exception entry
+// per failure, instead of partial reports from whichever goroutine failed
+// first. errs may hold nil entries for operations that succeeded, e.g. a
+// results slice indexed by task; they count towards the total. Events carry
+// a "multi_error" context with the operation and the failed and total
+// counts. With Separate, each failure becomes its own event instead, all
+// tagged with the same "error_group" and with its index in errs. It
+// returns the IDs of the captured events, none when no error is set.
+//
+//	errs := make([]error, len(shards))
+//	var wg sync.WaitGroup
+//	for i, shard := range shards {
+//		wg.Add(1)
+//		go func() {
+//			defer wg.Done()
+//			errs[i] = shard.Sync(ctx)
+//		}()
+//	}
+//	wg.Wait()
+//	sentrykit.CaptureMultiError(c, errs, sentrykit.MultiErrorConfig{Operation: "shards.sync"})
+func CaptureMultiError(c fiber.Ctx, errs []error, config ...MultiErrorConfig) []sentry.EventID {
+	var cfg MultiErrorConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	var failed []error
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	hub := activeHub(c)
+	if hub == nil {
+		return nil
+	}
+
+	data := map[string]interface{}{
+		"failed": len(failed),
+		"total":  len(errs),
+	}
+	if cfg.Operation != "" {
+		data["operation"] = cfg.Operation
+	}
+
+	if !cfg.Separate {
+		var err error = &multiError{operation: cfg.Operation, errs: failed, total: len(errs)}
+		if len(failed) == 1 {
+			err = failed[0]
+		}
+		var eventID *sentry.EventID
+		hub.WithScope(func(scope *sentry.Scope) {
+			scope.SetContext("multi_error", data)
+			eventID = hub.CaptureException(err)
+		})
+		if eventID == nil {
+			return nil
+		}
+		return []sentry.EventID{*eventID}
+	}
+
+	group := fmt.Sprintf("%016x", rand.Uint64())
+	data["group"] = group
+
+	var eventIDs []sentry.EventID
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		hub.WithScope(func(scope *sentry.Scope) {
+			item := make(map[string]interface{}, len(data)+1)
+			for key, value := range data {
+				item[key] = value
+			}
+			item["index"] = i
+			scope.SetTag("error_group", group)
+			scope.SetContext("multi_error", item)
+			if eventID := hub.CaptureException(err); eventID != nil {
+				eventIDs = append(eventIDs, *eventID)
+			}
+		})
+	}
+	return eventIDs
+}
@@ -0,0 +1,46 @@
+package sentrykit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// recoverValue reports a recovered panic value on the hub. Errors become
+// exceptions with their type and wrapped chain, strings become message
+// events, and any other value is serialized into the "panic" context.
+func recoverValue(ctx context.Context, hub *sentry.Hub, value interface{}) *sentry.EventID {
+	var eventID *sentry.EventID
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetTag("panic_type", fmt.Sprintf("%T", value))
+
+		switch v := value.(type) {
+		case error, string:
+			eventID = hub.RecoverWithContext(ctx, v)
+		default:
+			scope.SetContext("panic", map[string]interface{}{
+				"type":  fmt.Sprintf("%T", v),
+				"value": panicValueContext(v),
+			})
+			eventID = hub.RecoverWithContext(ctx, fmt.Sprintf("panic: %v", v))
+		}
+	})
+	return eventID
+}
+
+// panicValueContext converts a panic value to JSON-compatible data, falling
+// back to its formatted representation when it can't be marshaled
+func panicValueContext(value interface{}) interface{} {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%+v", value)
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return fmt.Sprintf("%+v", value)
+	}
+	return data
+}
@@ -0,0 +1,131 @@
+package sentrykit
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// payloadGuardPriority runs the payload guard after all other processors
+const payloadGuardPriority = 1 << 30
+
+// PayloadLimitsConfig sets size budgets for outgoing events, so they are
+// truncated instead of rejected at ingest for exceeding size limits
+type PayloadLimitsConfig struct {
+	// MaxEventBytes is the budget for the serialized event (0 = no guard)
+	MaxEventBytes int
+
+	// MaxContextBytes is the budget per context and per extra value
+	// (default: MaxEventBytes / 8)
+	MaxContextBytes int
+
+	// MaxBreadcrumbs keeps only the most recent breadcrumbs (default: 100)
+	MaxBreadcrumbs int
+
+	// MaxBodyBytes truncates the request body (default: 8 KiB)
+	MaxBodyBytes int
+}
+
+// truncatedMarker replaces a value that was removed for size
+const truncatedMarker = "[truncated by sentrykit: too large]"
+
+// payloadGuardProcessor returns the processor enforcing the budgets
+func payloadGuardProcessor(limits PayloadLimitsConfig) EventProcessor {
+	if limits.MaxContextBytes <= 0 {
+		limits.MaxContextBytes = limits.MaxEventBytes / 8
+	}
+	if limits.MaxBreadcrumbs <= 0 {
+		limits.MaxBreadcrumbs = 100
+	}
+	if limits.MaxBodyBytes <= 0 {
+		limits.MaxBodyBytes = 8 << 10
+	}
+
+	return func(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+		if eventSize(event) <= limits.MaxEventBytes {
+			return event
+		}
+
+		var truncated []string
+
+		// Cheapest, least valuable data first
+		if event.Request != nil && len(event.Request.Data) > limits.MaxBodyBytes {
+			event.Request.Data = event.Request.Data[:limits.MaxBodyBytes]
+			truncated = append(truncated, "request.data")
+		}
+		if len(event.Breadcrumbs) > limits.MaxBreadcrumbs {
+			event.Breadcrumbs = event.Breadcrumbs[len(event.Breadcrumbs)-limits.MaxBreadcrumbs:]
+			truncated = append(truncated, "breadcrumbs")
+		}
+		for _, key := range sortedKeys(event.Contexts) {
+			if key != "trace" && valueSize(event.Contexts[key]) > limits.MaxContextBytes {
+				event.Contexts[key] = sentry.Context{"value": truncatedMarker}
+				truncated = append(truncated, "contexts."+key)
+			}
+		}
+		for key, value := range event.Extra {
+			if valueSize(value) > limits.MaxContextBytes {
+				event.Extra[key] = truncatedMarker
+				truncated = append(truncated, "extra."+key)
+			}
+		}
+
+		// Still too large: drop breadcrumbs oldest first
+		for len(event.Breadcrumbs) > 0 && eventSize(event) > limits.MaxEventBytes {
+			drop := (len(event.Breadcrumbs) + 1) / 2
+			event.Breadcrumbs = event.Breadcrumbs[drop:]
+			truncated = append(truncated, "breadcrumbs")
+		}
+
+		if len(truncated) > 0 {
+			if event.Extra == nil {
+				event.Extra = make(map[string]interface{})
+			}
+			event.Extra["sentrykit_truncated"] = dedupe(truncated)
+		}
+		return event
+	}
+}
+
+// eventSize returns the serialized size of the event
+func eventSize(event *sentry.Event) int {
+	data, err := json.Marshal(event)
+	if err != nil {
+		// Marshaling failures are reported by the transport
+		return 0
+	}
+	return len(data)
+}
+
+// valueSize returns the serialized size of a value
+func valueSize(value interface{}) int {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
+
+// sortedKeys returns the context keys in a stable order
+func sortedKeys(contexts map[string]sentry.Context) []string {
+	keys := make([]string, 0, len(contexts))
+	for key := range contexts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// dedupe removes repeated entries, keeping the first occurrence
+func dedupe(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	result := values[:0]
+	for _, value := range values {
+		if _, ok := seen[value]; !ok {
+			seen[value] = struct{}{}
+			result = append(result, value)
+		}
+	}
+	return result
+}
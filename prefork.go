@@ -0,0 +1,49 @@
+package sentrykit
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+)
+
+// InitForApp initializes Sentry for a Fiber app, handling prefork mode.
+// With prefork every child re-executes the binary and runs InitForApp with
+// its own client; events are tagged with the process role (master/child),
+// PID and parent PID, since Fiber does not expose a child index. It also
+// flushes buffered events when the app shuts down.
+func InitForApp(app *fiber.App, cfg Config) error {
+	if err := Init(cfg); err != nil {
+		return err
+	}
+
+	role := "master"
+	if fiber.IsChild() {
+		role = "child"
+	}
+
+	sentry.ConfigureScope(func(scope *sentry.Scope) {
+		scope.SetTag("process_role", role)
+		scope.SetTag("pid", strconv.Itoa(os.Getpid()))
+		if role == "child" {
+			scope.SetTag("ppid", strconv.Itoa(os.Getppid()))
+		}
+	})
+	recordGlobalScopeBaseline()
+
+	// Record spawned children in the master so child crashes have context
+	app.Hooks().OnFork(func(pid int) error {
+		AddBreadcrumb("Prefork child started", "prefork", map[string]interface{}{
+			"pid": pid,
+		})
+		return nil
+	})
+
+	app.Hooks().OnShutdown(func() error {
+		Close()
+		return nil
+	})
+
+	return nil
+}
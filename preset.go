@@ -0,0 +1,69 @@
+package sentrykit
+
+// Presets for Config.Preset
+const (
+	PresetDevelopment = "development"
+	PresetStaging     = "staging"
+	PresetProduction  = "production"
+)
+
+// presetMaxEventBytes is the event size budget of the staging and
+// production presets, Sentry's limit for uncompressed events
+const presetMaxEventBytes = 1 << 20
+
+// validPreset reports whether name is empty or a known preset
+func validPreset(name string) bool {
+	switch name {
+	case "", PresetDevelopment, PresetStaging, PresetProduction:
+		return true
+	}
+	return false
+}
+
+// withPreset returns cfg with the defaults of cfg.Preset filled into the
+// fields left at their zero value, so explicit settings win:
+//
+//   - development: Debug (logging, scope diffs and event checks), all
+//     traces, DevMode
+//   - staging: half of the traces, self-monitoring, internal frames
+//     stripped and the payload guard
+//   - production: 10% of the traces, self-monitoring, internal frames
+//     stripped and the payload guard
+//
+// All presets set Environment to the preset name and attach stack traces.
+// They leave out scrubbing and deduplication: sensitive headers, route
+// params and replay bodies are scrubbed by the middleware in every
+// environment, so there is nothing to turn on, and the kit has no event
+// deduplication.
+func (cfg Config) withPreset() Config {
+	if cfg.Preset == "" || !validPreset(cfg.Preset) {
+		return cfg
+	}
+
+	if cfg.Environment == "" {
+		cfg.Environment = cfg.Preset
+	}
+	cfg.AttachStacktrace = true
+
+	tracesSampleRate := 0.1
+	switch cfg.Preset {
+	case PresetDevelopment:
+		tracesSampleRate = 1.0
+		cfg.Debug = true
+		cfg.DevMode = true
+	case PresetStaging:
+		tracesSampleRate = 0.5
+	}
+	if cfg.TracesSampleRate == 0 && cfg.TracesSampler == nil {
+		cfg.TracesSampleRate = tracesSampleRate
+	}
+
+	if cfg.Preset != PresetDevelopment {
+		cfg.SelfMonitor = true
+		cfg.StripInternalFrames = true
+		if cfg.PayloadLimits.MaxEventBytes == 0 {
+			cfg.PayloadLimits.MaxEventBytes = presetMaxEventBytes
+		}
+	}
+	return cfg
+}
@@ -0,0 +1,88 @@
+package sentrykit
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// EventProcessor modifies an event before it is sent or drops it by returning nil
+type EventProcessor func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event
+
+// namedProcessor is a registered processor with its ordering information
+type namedProcessor struct {
+	name      string
+	priority  int
+	processor EventProcessor
+}
+
+// eventProcessors is the ordered processor pipeline run from BeforeSend
+var eventProcessors struct {
+	mu         sync.RWMutex
+	processors []namedProcessor
+}
+
+// RegisterProcessor adds a named processor to the event pipeline.
+// Processors run in ascending priority order; processors with the same
+// priority run in registration order. Registering an existing name
+// replaces that processor.
+func RegisterProcessor(name string, p func(*sentry.Event, *sentry.EventHint) *sentry.Event, priority int) {
+	eventProcessors.mu.Lock()
+	defer eventProcessors.mu.Unlock()
+
+	processors := removeProcessor(eventProcessors.processors, name)
+	processors = append(processors, namedProcessor{
+		name:      name,
+		priority:  priority,
+		processor: p,
+	})
+	sort.SliceStable(processors, func(i, j int) bool {
+		return processors[i].priority < processors[j].priority
+	})
+	eventProcessors.processors = processors
+}
+
+// UnregisterProcessor removes a named processor from the event pipeline
+func UnregisterProcessor(name string) {
+	eventProcessors.mu.Lock()
+	defer eventProcessors.mu.Unlock()
+	eventProcessors.processors = removeProcessor(eventProcessors.processors, name)
+}
+
+// removeProcessor returns processors without the one registered under name
+func removeProcessor(processors []namedProcessor, name string) []namedProcessor {
+	result := make([]namedProcessor, 0, len(processors))
+	for _, p := range processors {
+		if p.name != name {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// runProcessors runs the event through the processor pipeline
+func runProcessors(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+	eventProcessors.mu.RLock()
+	defer eventProcessors.mu.RUnlock()
+
+	for _, p := range eventProcessors.processors {
+		if event = runProcessor(p, event, hint); event == nil {
+			return nil
+		}
+	}
+	return event
+}
+
+// runProcessor runs a single processor. A panicking processor is reported
+// as an internal failure and the event continues unchanged.
+func runProcessor(p namedProcessor, event *sentry.Event, hint *sentry.EventHint) (result *sentry.Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			reportInternal("processor", fmt.Sprintf("processor %q panicked: %v", p.name, r))
+			result = event
+		}
+	}()
+	return p.processor(event, hint)
+}
@@ -0,0 +1,45 @@
+package sentrykit
+
+import (
+	"context"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+)
+
+// InjectTrace writes the request's trace headers (sentry-trace, baggage)
+// into carrier, e.g. Kafka headers or SQS/NATS message attributes, so a
+// consumer can continue the trace with ExtractTrace
+func InjectTrace(c fiber.Ctx, carrier map[string]string) {
+	InjectTraceContext(c.UserContext(), carrier)
+}
+
+// InjectTraceContext is InjectTrace for code holding a context instead of a
+// Fiber context. Without an active span it falls back to the hub's
+// propagation context.
+func InjectTraceContext(ctx context.Context, carrier map[string]string) {
+	if span := sentry.SpanFromContext(ctx); span != nil {
+		carrier[sentry.SentryTraceHeader] = span.ToSentryTrace()
+		if baggage := span.ToBaggage(); baggage != "" {
+			carrier[sentry.SentryBaggageHeader] = baggage
+		}
+		return
+	}
+
+	hub := sentry.GetHubFromContext(ctx)
+	if hub == nil {
+		hub = sentry.CurrentHub()
+	}
+	carrier[sentry.SentryTraceHeader] = hub.GetTraceparent()
+	if baggage := hub.GetBaggage(); baggage != "" {
+		carrier[sentry.SentryBaggageHeader] = baggage
+	}
+}
+
+// ExtractTrace returns a span option continuing the trace injected into
+// carrier by InjectTrace. Pass it when starting the consumer transaction:
+//
+//	transaction := sentry.StartTransaction(ctx, "orders.process", sentrykit.ExtractTrace(msg.Headers))
+func ExtractTrace(carrier map[string]string) sentry.SpanOption {
+	return sentry.ContinueFromHeaders(carrier[sentry.SentryTraceHeader], carrier[sentry.SentryBaggageHeader])
+}
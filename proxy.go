@@ -0,0 +1,76 @@
+package sentrykit
+
+import (
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/proxy"
+	"github.com/valyala/fasthttp"
+)
+
+// TraceProxy wraps a handler built on Fiber's proxy middleware (Forward,
+// Balancer, or one calling proxy.Do) so the upstream call gets an
+// "http.client" child span and carries sentry-trace/baggage headers, keeping
+// the trace intact through the gateway. upstream describes the target in the
+// span (e.g. "users-service").
+//
+//	app.Use(sentrykit.New())
+//	app.Get("/users/*", sentrykit.TraceProxy("users", proxy.Balancer(proxy.Config{
+//	    Servers: []string{"users-1:3000", "users-2:3000"},
+//	})))
+func TraceProxy(upstream string, handler fiber.Handler) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		method := strings.Clone(c.Method())
+		span := sentry.StartSpan(c.UserContext(), "http.client",
+			sentry.WithDescription(method+" "+upstream),
+		)
+		defer span.Finish()
+		span.SetData("http.request.method", method)
+		span.SetData("server.address", upstream)
+
+		// The proxied request is the incoming one, so propagate the trace
+		// through its headers and restore the client's values afterwards
+		header := &c.Request().Header
+		previousTrace := string(header.Peek(sentry.SentryTraceHeader))
+		previousBaggage := string(header.Peek(sentry.SentryBaggageHeader))
+		header.Set(sentry.SentryTraceHeader, span.ToSentryTrace())
+		header.Set(sentry.SentryBaggageHeader, span.ToBaggage())
+		defer func() {
+			restoreHeader(header, sentry.SentryTraceHeader, previousTrace)
+			restoreHeader(header, sentry.SentryBaggageHeader, previousBaggage)
+		}()
+
+		err := handler(c)
+		if err != nil {
+			span.Status = spanStatusFromError(err)
+			return err
+		}
+
+		code := c.Response().StatusCode()
+		span.SetData("http.response.status_code", code)
+		span.Status = sentry.HTTPtoSpanStatus(code)
+		return nil
+	}
+}
+
+// ProxyForward is proxy.Forward with trace propagation (see TraceProxy)
+func ProxyForward(addr string, clients ...*fasthttp.Client) fiber.Handler {
+	return TraceProxy(addr, proxy.Forward(addr, clients...))
+}
+
+// ProxyDo is proxy.Do with trace propagation (see TraceProxy)
+func ProxyDo(c fiber.Ctx, addr string, clients ...*fasthttp.Client) error {
+	return TraceProxy(addr, func(c fiber.Ctx) error {
+		return proxy.Do(c, addr, clients...)
+	})(c)
+}
+
+// restoreHeader sets a header back to its previous value, or removes it
+func restoreHeader(header *fasthttp.RequestHeader, key, value string) {
+	if value == "" {
+		header.Del(key)
+		return
+	}
+	header.Set(key, value)
+}
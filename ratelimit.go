@@ -0,0 +1,137 @@
+package sentrykit
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+)
+
+// RateLimitConfig configures reporting of requests rejected by Fiber's limiter
+type RateLimitConfig struct {
+	// Threshold is the number of rejections per endpoint (method and
+	// normalized path) and client IP bucket within Window that triggers a
+	// warning event (0 = breadcrumbs only)
+	Threshold int
+
+	// Window over which rejections are counted (default: 1 minute)
+	Window time.Duration
+
+	// LimitReached renders the response (default: 429 status)
+	LimitReached fiber.Handler
+}
+
+// rateLimitCounter counts rejections per key in fixed windows
+type rateLimitCounter struct {
+	mu          sync.Mutex
+	window      time.Duration
+	windowStart time.Time
+	counts      map[string]int
+}
+
+// add increments the count for key and returns the new value
+func (r *rateLimitCounter) add(key string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := now()
+	if current.Sub(r.windowStart) >= r.window {
+		r.windowStart = current
+		r.counts = make(map[string]int)
+	}
+	r.counts[key]++
+	return r.counts[key]
+}
+
+// LimitReachedHandler returns a LimitReached handler for Fiber's limiter
+// middleware that records a breadcrumb for each rejected request and a
+// warning event once an endpoint/IP bucket crosses the configured
+// threshold. The limiter rejects requests before a route matched, so
+// endpoints are keyed by method and normalized path (see normalizePath).
+// Register the limiter middleware after the Sentry middleware.
+func LimitReachedHandler(config ...RateLimitConfig) fiber.Handler {
+	var cfg RateLimitConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = time.Minute
+	}
+	if cfg.LimitReached == nil {
+		cfg.LimitReached = func(c fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusTooManyRequests)
+		}
+	}
+
+	counter := &rateLimitCounter{window: cfg.Window}
+
+	return func(c fiber.Ctx) error {
+		hub := GetHubFromContext(c)
+		method := strings.Clone(c.Method())
+		route := normalizePath(c.Path())
+		bucket := ipBucket(c.IP())
+		count := counter.add(method + " " + route + "|" + bucket)
+
+		data := map[string]interface{}{
+			"method":    method,
+			"route":     route,
+			"ip_bucket": bucket,
+			"count":     count,
+			"window":    cfg.Window.String(),
+		}
+
+		hub.AddBreadcrumb(&sentry.Breadcrumb{
+			Message:  "Rate limit reached",
+			Category: "rate_limit",
+			Data:     data,
+			Level:    sentry.LevelWarning,
+		}, nil)
+
+		// Report once per window when the threshold is crossed
+		if cfg.Threshold > 0 && count == cfg.Threshold {
+			hub.WithScope(func(scope *sentry.Scope) {
+				scope.SetLevel(sentry.LevelWarning)
+				scope.SetTag("error_category", "rate_limit")
+				scope.SetTag("ip_bucket", bucket)
+				scope.SetContext("rate_limit", data)
+				scope.SetFingerprint([]string{"rate_limit", method, route, bucket})
+				hub.CaptureMessage(fmt.Sprintf("Rate limit threshold exceeded on %s %s", method, route))
+			})
+		}
+
+		return cfg.LimitReached(c)
+	}
+}
+
+// idSegmentPattern matches path segments that are likely IDs: numbers,
+// UUIDs and long hex strings
+var idSegmentPattern = regexp.MustCompile(`^([0-9]+|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[0-9a-fA-F]{16,})$`)
+
+// normalizePath replaces ID segments of path with ":id", so requests to
+// the same endpoint share a key before a route matched
+func normalizePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if idSegmentPattern.MatchString(segment) {
+			segments[i] = ":id"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// ipBucket groups an IP into its /24 (IPv4) or /48 (IPv6) network
+func ipBucket(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ip
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String() + "/24"
+	}
+	return parsed.Mask(net.CIDRMask(48, 128)).String() + "/48"
+}
@@ -0,0 +1,53 @@
+package sentrykit
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/limiter"
+)
+
+func TestLimitReachedHandlerKeysByEndpoint(t *testing.T) {
+	transport := bindTestClient(t)
+
+	app := fiber.New()
+	app.Use(New())
+	app.Use(limiter.New(limiter.Config{
+		Max:        1,
+		Expiration: time.Minute,
+		LimitReached: LimitReachedHandler(RateLimitConfig{
+			Threshold: 2,
+		}),
+	}))
+	app.Get("/orders/:id", func(c fiber.Ctx) error { return c.SendString("order") })
+	app.Get("/users/:id", func(c fiber.Ctx) error { return c.SendString("user") })
+
+	// One allowed request, then one rejection per endpoint: no bucket
+	// reaches the threshold unless endpoints share a key
+	for _, path := range []string{"/orders/1", "/orders/2", "/users/3"} {
+		if _, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, event := range transport.Events() {
+		if event.Tags["error_category"] == "rate_limit" {
+			t.Fatalf("rejections of different endpoints counted together: %s", event.Message)
+		}
+	}
+
+	if _, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/users/4", nil)); err != nil {
+		t.Fatal(err)
+	}
+	event := waitForEvent(transport, time.Second, func(event *sentry.Event) bool {
+		return event.Tags["error_category"] == "rate_limit"
+	})
+	if event == nil {
+		t.Fatal("threshold crossing not reported")
+	}
+	if want := "Rate limit threshold exceeded on GET /users/:id"; event.Message != want {
+		t.Errorf("message = %q, want %q", event.Message, want)
+	}
+}
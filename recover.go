@@ -0,0 +1,48 @@
+package sentrykit
+
+import (
+	"github.com/gofiber/fiber/v3"
+	fiberrecover "github.com/gofiber/fiber/v3/middleware/recover"
+)
+
+// recoveredPanicKey marks requests whose panic was already captured by the
+// recover middleware integration
+const recoveredPanicKey = "sentry_recovered_panic"
+
+// RecoverConfig returns a config for Fiber's recover middleware that captures
+// panics on the request hub before recover turns them into errors. Without
+// it, a recover middleware registered after this one swallows the panic and
+// only a stackless error reaches Sentry. Any StackTraceHandler in config
+// still runs after the capture.
+//
+//	app.Use(sentrykit.New())
+//	app.Use(recover.New(sentrykit.RecoverConfig()))
+func RecoverConfig(config ...fiberrecover.Config) fiberrecover.Config {
+	var cfg fiberrecover.Config
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	next := cfg.StackTraceHandler
+	if !cfg.EnableStackTrace {
+		next = nil
+	}
+
+	cfg.EnableStackTrace = true
+	cfg.StackTraceHandler = func(c fiber.Ctx, e any) {
+		// Runs inside recover's deferred function, so the stack still
+		// points at the panic site
+		eventID := recoverValue(c.UserContext(), GetHubFromContext(c), e)
+		c.Locals(recoveredPanicKey, true)
+		if eventID != nil {
+			// NewErrorHandler and ErrorPage see the panic as captured
+			c.Locals(capturedEventKey, eventID)
+		}
+
+		if next != nil {
+			next(c, e)
+		}
+	}
+
+	return cfg
+}
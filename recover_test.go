@@ -0,0 +1,47 @@
+package sentrykit
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+	fiberrecover "github.com/gofiber/fiber/v3/middleware/recover"
+)
+
+func TestRecoverConfigWithErrorHandlerCapturesPanicOnce(t *testing.T) {
+	transport := bindTestClient(t)
+
+	app := fiber.New(fiber.Config{ErrorHandler: NewErrorHandler()})
+	app.Use(New())
+	app.Use(fiberrecover.New(RecoverConfig()))
+	app.Get("/", func(c fiber.Ctx) error {
+		panic("boom")
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", resp.StatusCode)
+	}
+
+	// app.Test returns once the error handler ran
+	if waitForEvent(transport, time.Second, func(event *sentry.Event) bool {
+		return event.Type == "transaction"
+	}) == nil {
+		t.Fatal("transaction not sent")
+	}
+
+	var errors int
+	for _, event := range transport.Events() {
+		if event.Type != "transaction" {
+			errors++
+		}
+	}
+	if errors != 1 {
+		t.Errorf("captured %d error events for one panic, want 1", errors)
+	}
+}
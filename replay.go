@@ -0,0 +1,269 @@
+package sentrykit
+
+import (
+	"encoding/json"
+	"mime"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// defaultReplayMaxBody caps replayed request bodies
+const defaultReplayMaxBody = 64 << 10
+
+// filteredValue replaces redacted values
+const filteredValue = "[Filtered]"
+
+// replayBundle is a sanitized description of a request, sufficient to
+// reproduce it
+type replayBundle struct {
+	Method        string            `json:"method"`
+	URL           string            `json:"url"`
+	Headers       map[string]string `json:"headers"`
+	Body          string            `json:"body,omitempty"`
+	BodyTruncated bool              `json:"body_truncated,omitempty"`
+	BodyOmitted   string            `json:"body_omitted,omitempty"`
+}
+
+// replayBundle builds the sanitized bundle of the request. Captured headers
+// are kept; headers that are never captured but present are listed with a
+// "[Filtered]" value, so the reproducer knows to supply them.
+func (r *requestState) replayBundle() replayBundle {
+	denylist := r.replayDenylist()
+
+	bundle := replayBundle{
+		Method:  r.req.Method,
+		URL:     r.req.BaseURL + r.req.Path,
+		Headers: make(map[string]string, len(r.req.Headers)),
+	}
+	if r.req.Query != "" {
+		bundle.URL += "?" + redactQuery(r.req.Query, denylist)
+	}
+
+	for key, value := range r.req.Headers {
+		bundle.Headers[key] = value
+	}
+	secret := []string{"Authorization", "Cookie", "X-Api-Key"}
+	if r.cfg.DebugHeader != "" {
+		secret = append(secret, r.cfg.DebugHeader)
+	}
+	for _, key := range secret {
+		if r.req.Header != nil && r.req.Header(key) != "" {
+			bundle.Headers[key] = filteredValue
+		}
+	}
+
+	if r.req.Body == nil {
+		bundle.BodyOmitted = "unavailable"
+		return bundle
+	}
+	body := r.req.Body()
+	if len(body) == 0 {
+		return bundle
+	}
+
+	redacted, ok := redactBody(r.req.Header("Content-Type"), body, denylist)
+	switch {
+	case !ok:
+		bundle.BodyOmitted = "unsupported content type"
+	case len(redacted) > r.replayMaxBody():
+		bundle.Body = truncateUTF8(redacted, r.replayMaxBody())
+		bundle.BodyTruncated = true
+	default:
+		bundle.Body = redacted
+	}
+	return bundle
+}
+
+// replayDenylist returns the field name fragments redacted from replays
+func (r *requestState) replayDenylist() []string {
+	if r.cfg.ReplayRedactFields == nil {
+		return DefaultParamDenylist
+	}
+	return r.cfg.ReplayRedactFields
+}
+
+// replayMaxBody returns the body size cap of replays
+func (r *requestState) replayMaxBody() int {
+	if r.cfg.ReplayMaxBody <= 0 {
+		return defaultReplayMaxBody
+	}
+	return r.cfg.ReplayMaxBody
+}
+
+// redactBody returns the body with denylisted fields filtered. JSON and
+// form bodies are redacted field by field and plain text is kept; other
+// bodies can't be redacted and are omitted (ok = false).
+func redactBody(contentType string, body []byte, denylist []string) (string, bool) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var data interface{}
+		if err := json.Unmarshal(body, &data); err != nil {
+			return "", false
+		}
+		redacted, err := json.Marshal(redactJSON(data, denylist))
+		if err != nil {
+			return "", false
+		}
+		return string(redacted), true
+	case mediaType == "application/x-www-form-urlencoded":
+		return redactQuery(string(body), denylist), true
+	case mediaType == "text/plain" && utf8.Valid(body):
+		return string(body), true
+	}
+	return "", false
+}
+
+// redactJSON filters the values of denylisted keys at any depth
+func redactJSON(value interface{}, denylist []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if isDenylisted(key, denylist) {
+				v[key] = filteredValue
+			} else {
+				v[key] = redactJSON(field, denylist)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactJSON(item, denylist)
+		}
+	}
+	return value
+}
+
+// redactQuery filters the values of denylisted keys of a query string,
+// keeping the key order stable
+func redactQuery(query string, denylist []string) string {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return filteredValue
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		for _, value := range values[key] {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			if isDenylisted(key, denylist) {
+				value = filteredValue
+			}
+			b.WriteString(url.QueryEscape(key) + "=" + url.QueryEscape(value))
+		}
+	}
+	return b.String()
+}
+
+// isDenylisted reports whether a field name contains a denylisted fragment
+func isDenylisted(name string, denylist []string) bool {
+	lower := strings.ToLower(name)
+	for _, denied := range denylist {
+		if strings.Contains(lower, strings.ToLower(denied)) {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateUTF8 shortens s to at most n bytes without splitting a rune
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// maxCurlContext is the longest curl command added as context; longer ones
+// are attached as a file
+const maxCurlContext = 4 << 10
+
+// curlCommand renders the bundle as a ready-to-run curl command. Headers
+// curl sets itself are left out.
+func (b replayBundle) curlCommand() string {
+	var cmd strings.Builder
+	if b.BodyTruncated || b.BodyOmitted != "" {
+		cmd.WriteString("# request body truncated or omitted\n")
+	}
+	cmd.WriteString("curl -X " + b.Method + " " + shellQuote(b.URL))
+
+	keys := make([]string, 0, len(b.Headers))
+	for key := range b.Headers {
+		if !strings.EqualFold(key, "Host") && !strings.EqualFold(key, "Content-Length") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		cmd.WriteString(" \\\n  -H " + shellQuote(key+": "+b.Headers[key]))
+	}
+
+	if b.Body != "" {
+		cmd.WriteString(" \\\n  --data-raw " + shellQuote(b.Body))
+	}
+	return cmd.String()
+}
+
+// shellQuote quotes s for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// replayProcessor adds the request reproduction to error events: the
+// replay bundle ("request-replay.json"), a HAR entry ("request.har") and/or
+// a curl command, as the "reproduce" context or, when long, attached as
+// "request.curl.sh"
+func replayProcessor(r *requestState) sentry.EventProcessor {
+	return func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+		if event.Type != "" {
+			return event
+		}
+		bundle := r.replayBundle()
+
+		if r.cfg.HARAttachment {
+			if attachment := r.harAttachment(); attachment != nil {
+				event.Attachments = append(event.Attachments, attachment)
+			}
+		}
+
+		if r.cfg.ReplayBundle {
+			if payload, err := json.MarshalIndent(bundle, "", "  "); err == nil {
+				event.Attachments = append(event.Attachments, &sentry.Attachment{
+					Filename:    "request-replay.json",
+					ContentType: "application/json",
+					Payload:     payload,
+				})
+			}
+		}
+
+		if r.cfg.CurlCommand {
+			curl := bundle.curlCommand()
+			if len(curl) <= maxCurlContext {
+				if event.Contexts == nil {
+					event.Contexts = make(map[string]sentry.Context)
+				}
+				event.Contexts["reproduce"] = sentry.Context{"curl": curl}
+			} else {
+				event.Attachments = append(event.Attachments, &sentry.Attachment{
+					Filename:    "request.curl.sh",
+					ContentType: "text/x-shellscript",
+					Payload:     []byte(curl + "\n"),
+				})
+			}
+		}
+		return event
+	}
+}
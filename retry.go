@@ -0,0 +1,102 @@
+package sentrykit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+)
+
+// RetryConfig configures the backoff of Retry
+type RetryConfig struct {
+	// Backoff is the wait before the second attempt (default: 100ms)
+	Backoff time.Duration
+
+	// Multiplier grows the wait after each failed attempt (default: 2)
+	Multiplier float64
+
+	// MaxBackoff caps the wait between attempts (default: 5s)
+	MaxBackoff time.Duration
+}
+
+// Retry runs fn up to attempts times with exponential backoff, stopping
+// early when the request context is done. Every attempt is recorded as a
+// "retry" breadcrumb with its duration and the following wait; only the
+// final failure is captured, with a "retry" context, so transient failures
+// create no events but the retry history shows when it does fail. It
+// returns the last error; the request is marked as captured, so the
+// middleware and NewErrorHandler don't capture it again when it's returned.
+func Retry(c fiber.Ctx, name string, attempts int, fn func(attempt int) error, config ...RetryConfig) error {
+	var cfg RetryConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = 100 * time.Millisecond
+	}
+	if cfg.Multiplier < 1 {
+		cfg.Multiplier = 2
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 5 * time.Second
+	}
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	ctx := c.UserContext()
+	backoff := cfg.Backoff
+	for attempt := 1; ; attempt++ {
+		start := now()
+		err := fn(attempt)
+		data := map[string]interface{}{
+			"attempt":     attempt,
+			"attempts":    attempts,
+			"duration_ms": milliseconds(since(start)),
+		}
+
+		if err == nil {
+			AddBreadcrumbFromContext(c, fmt.Sprintf("%s succeeded on attempt %d", name, attempt), "retry", data)
+			return nil
+		}
+
+		data["error"] = err.Error()
+		if attempt == attempts {
+			AddBreadcrumbFromContext(c, fmt.Sprintf("%s failed on attempt %d", name, attempt), "retry", data,
+				BreadcrumbOptions{Level: sentry.LevelError, Type: "error"})
+			return captureRetryFailure(c, name, attempt, err)
+		}
+
+		data["backoff_ms"] = milliseconds(backoff)
+		AddBreadcrumbFromContext(c, fmt.Sprintf("%s failed on attempt %d, retrying", name, attempt), "retry", data,
+			BreadcrumbOptions{Level: sentry.LevelWarning})
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			err = fmt.Errorf("%s: retry aborted after attempt %d: %w (%w)", name, attempt, err, ctx.Err())
+			return captureRetryFailure(c, name, attempt, err)
+		}
+		backoff = min(time.Duration(float64(backoff)*cfg.Multiplier), cfg.MaxBackoff)
+	}
+}
+
+// captureRetryFailure captures the final failure of a retried operation on
+// the request hub and records the event as the request's captured event
+func captureRetryFailure(c fiber.Ctx, name string, attempts int, err error) error {
+	hub := GetHubFromContext(c)
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetTag("retry", name)
+		scope.SetContext("retry", map[string]interface{}{
+			"operation": name,
+			"attempts":  attempts,
+		})
+		if eventID := hub.CaptureException(err); eventID != nil {
+			c.Locals(capturedEventKey, eventID)
+		}
+	})
+	return err
+}
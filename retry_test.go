@@ -0,0 +1,40 @@
+package sentrykit
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestRetryFailureReturnedFromHandlerCapturedOnce(t *testing.T) {
+	transport := bindTestClient(t)
+
+	app := fiber.New(fiber.Config{ErrorHandler: NewErrorHandler()})
+	app.Use(New())
+	app.Get("/", func(c fiber.Ctx) error {
+		return Retry(c, "payments.charge", 2, func(attempt int) error {
+			return errors.New("provider unavailable")
+		}, RetryConfig{Backoff: time.Millisecond})
+	})
+
+	if _, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	var failures []*sentry.Event
+	for _, event := range transport.Events() {
+		if event.Type != "transaction" {
+			failures = append(failures, event)
+		}
+	}
+	if len(failures) != 1 {
+		t.Fatalf("captured %d events for one retry failure, want 1", len(failures))
+	}
+	if failures[0].Tags["retry"] != "payments.charge" {
+		t.Errorf("event tags = %v, want the retry tag", failures[0].Tags)
+	}
+}
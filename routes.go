@@ -0,0 +1,98 @@
+package sentrykit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+)
+
+// RouteSnapshotConfig configures ReportRoutes
+type RouteSnapshotConfig struct {
+	// Hash lists each route as its method and a hash of its path, for
+	// services whose paths must not leave the premises; snapshots of the
+	// same routes still compare equal
+	Hash bool
+
+	// IncludeMiddleware also lists routes registered with Use
+	// (default: only handler routes)
+	IncludeMiddleware bool
+}
+
+// ReportRoutes records the routes app serves, to confirm in Sentry what a
+// release actually served when investigating e.g. 404 spikes. It captures
+// an info event ("route-snapshot" fingerprint, so each release adds an event
+// to the same issue) with the sorted "METHOD path" list attached as
+// routes.json, and sets a "routes" context with the route count and a
+// digest of the list on the root scope, so every later event can be matched
+// to its route set. Call it once all routes are registered, or set
+// InstallConfig.ReportRoutes to report when the app starts listening.
+func ReportRoutes(app *fiber.App, config ...RouteSnapshotConfig) *sentry.EventID {
+	var cfg RouteSnapshotConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	routes := routeList(app, cfg)
+	digest := routesDigest(routes)
+	summary := map[string]interface{}{
+		"count":  len(routes),
+		"digest": digest,
+		"hashed": cfg.Hash,
+	}
+	sentry.ConfigureScope(func(scope *sentry.Scope) {
+		scope.SetContext("routes", summary)
+	})
+
+	payload, err := json.MarshalIndent(routes, "", "  ")
+	if err != nil {
+		reportInternal("marshal", err.Error())
+		return nil
+	}
+
+	var eventID *sentry.EventID
+	hub := sentry.CurrentHub()
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetLevel(sentry.LevelInfo)
+		scope.SetTag("route_digest", digest)
+		scope.SetFingerprint([]string{"route-snapshot"})
+		scope.AddAttachment(&sentry.Attachment{
+			Filename:    "routes.json",
+			ContentType: "application/json",
+			Payload:     payload,
+		})
+		eventID = hub.CaptureMessage(fmt.Sprintf("Route snapshot: %d routes", len(routes)))
+	})
+	return eventID
+}
+
+// routeList returns the sorted, deduplicated "METHOD path" routes of app
+func routeList(app *fiber.App, cfg RouteSnapshotConfig) []string {
+	seen := make(map[string]bool)
+	var routes []string
+	for _, route := range app.GetRoutes(!cfg.IncludeMiddleware) {
+		path := route.Path
+		if cfg.Hash {
+			sum := sha256.Sum256([]byte(path))
+			path = hex.EncodeToString(sum[:6])
+		}
+		entry := route.Method + " " + path
+		if !seen[entry] {
+			seen[entry] = true
+			routes = append(routes, entry)
+		}
+	}
+	sort.Strings(routes)
+	return routes
+}
+
+// routesDigest returns a short hash identifying a route list
+func routesDigest(routes []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(routes, "\n")))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,95 @@
+package sentrykit
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// TenantInfo describes the tenant and user a request belongs to, as
+// returned by MiddlewareConfig.TenantExtractor
+type TenantInfo struct {
+	ID     string // Tenant/account ID
+	Tier   string // Subscription tier, e.g. "free", "enterprise"
+	UserID string // Authenticated user ID
+}
+
+// tenantContextKey holds the extracted tenant on the request context
+type tenantContextKey struct{}
+
+// withTenant returns ctx carrying tenant
+func withTenant(ctx context.Context, tenant TenantInfo) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant extracted for the request, if any
+func TenantFromContext(ctx context.Context) (TenantInfo, bool) {
+	tenant, ok := ctx.Value(tenantContextKey{}).(TenantInfo)
+	return tenant, ok
+}
+
+// SamplingInput is what Config.TracesSampler decides on
+type SamplingInput struct {
+	// Name and Op of the transaction being started
+	Name string
+	Op   string
+
+	// Tenant extracted by the middleware's TenantExtractor, if HasTenant
+	Tenant    TenantInfo
+	HasTenant bool
+
+	// ParentSampled is the sampling decision of an incoming trace;
+	// return 1.0 or 0.0 to honor it
+	ParentSampled sentry.Sampled
+}
+
+// tracesSampler adapts a kit sampler to sentry-go
+func tracesSampler(sampler func(SamplingInput) float64) sentry.TracesSampler {
+	return func(ctx sentry.SamplingContext) float64 {
+		input := SamplingInput{
+			Name:          ctx.Span.Name,
+			Op:            ctx.Span.Op,
+			ParentSampled: ctx.Span.Sampled,
+		}
+		input.Tenant, input.HasTenant = TenantFromContext(ctx.Span.Context())
+		return sampler(input)
+	}
+}
+
+// StickySampler returns a TracesSampler that samples rate of users rather
+// than rate of requests: the decision is a hash of the tenant's UserID (or
+// tenant ID when there is no user), so a sampled user is traced on every
+// request. Incoming trace decisions are honored, and requests without a
+// tenant are sampled randomly with rate.
+func StickySampler(rate float64) func(input SamplingInput) float64 {
+	return func(input SamplingInput) float64 {
+		switch input.ParentSampled {
+		case sentry.SampledTrue:
+			return 1.0
+		case sentry.SampledFalse:
+			return 0.0
+		}
+
+		key := input.Tenant.UserID
+		if key == "" {
+			key = input.Tenant.ID
+		}
+		if !input.HasTenant || key == "" {
+			return rate
+		}
+
+		if hashFraction(key) < rate {
+			return 1.0
+		}
+		return 0.0
+	}
+}
+
+// hashFraction maps key uniformly onto [0, 1)
+func hashFraction(key string) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return float64(h.Sum64()) / (math.MaxUint64 + 1.0)
+}
@@ -0,0 +1,103 @@
+package sentrykit
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// maxTagKeyLength is the longest tag key Sentry accepts
+const maxTagKeyLength = 32
+
+// tagKeyPattern matches the characters Sentry allows in tag keys
+var tagKeyPattern = regexp.MustCompile(`^[a-zA-Z0-9_.:-]+$`)
+
+// validLevels are the event levels Sentry knows
+var validLevels = map[sentry.Level]bool{
+	sentry.LevelDebug:   true,
+	sentry.LevelInfo:    true,
+	sentry.LevelWarning: true,
+	sentry.LevelError:   true,
+	sentry.LevelFatal:   true,
+}
+
+// checkEvent returns the problems of an event that Sentry would reject,
+// truncate or display badly. Ingest drops such data without telling the
+// sender, so in debug mode they are logged before sending.
+func checkEvent(event *sentry.Event) []string {
+	var problems []string
+
+	if event.Type == "" {
+		if event.Message == "" && len(event.Exception) == 0 {
+			problems = append(problems, "event has neither a message nor an exception; it shows as <unlabeled event>")
+		}
+		for i, exception := range event.Exception {
+			switch {
+			case exception.Type == "" && exception.Value == "":
+				problems = append(problems, fmt.Sprintf("exception %d has neither type nor value; check the captured error's Error()", i))
+			case exception.Value == "":
+				problems = append(problems, fmt.Sprintf("exception %d (%s) has an empty value; its Error() returns \"\"", i, exception.Type))
+			}
+		}
+		if event.Level != "" && !validLevels[event.Level] {
+			problems = append(problems, fmt.Sprintf("unknown level %q; use the sentry.Level constants", event.Level))
+		}
+	}
+
+	for key, value := range event.Tags {
+		switch {
+		case key == "":
+			problems = append(problems, "tag with an empty key is dropped")
+		case len(key) > maxTagKeyLength:
+			problems = append(problems, fmt.Sprintf("tag key %q is over %d characters and is dropped; use a context for long keys", key, maxTagKeyLength))
+		case !tagKeyPattern.MatchString(key):
+			problems = append(problems, fmt.Sprintf("tag key %q may only contain letters, digits and _.:- and is dropped", key))
+		}
+		switch {
+		case len(value) > maxTagValueLength:
+			problems = append(problems, fmt.Sprintf("tag %q value is %d characters, over the %d limit, and is dropped; use truncated values or a context", key, len(value), maxTagValueLength))
+		case strings.Contains(value, "\n"):
+			problems = append(problems, fmt.Sprintf("tag %q value contains a newline and is dropped", key))
+		}
+	}
+
+	for name, context := range event.Contexts {
+		if kind, ok := context["type"]; ok {
+			if _, isString := kind.(string); !isString {
+				problems = append(problems, fmt.Sprintf("context %q has a %T type field; it must be a string", name, kind))
+			}
+		}
+		for key, value := range context {
+			if _, err := json.Marshal(value); err != nil {
+				problems = append(problems, fmt.Sprintf("context %q key %q holds a %T that can't be serialized: %v", name, key, value, err))
+			}
+		}
+	}
+
+	for key, value := range event.Extra {
+		if _, err := json.Marshal(value); err != nil {
+			problems = append(problems, fmt.Sprintf("extra %q holds a %T that can't be serialized: %v", key, value, err))
+		}
+	}
+
+	for i, part := range event.Fingerprint {
+		if part == "" {
+			problems = append(problems, fmt.Sprintf("fingerprint part %d is empty; groups may merge unexpectedly", i))
+		}
+	}
+
+	return problems
+}
+
+// logEventProblems checks an event in debug mode and logs what is wrong
+func logEventProblems(event *sentry.Event) {
+	if !debugEnabled() {
+		return
+	}
+	for _, problem := range checkEvent(event) {
+		debugf("event %s: %s", event.EventID, problem)
+	}
+}
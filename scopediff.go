@@ -0,0 +1,150 @@
+package sentrykit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// scopeSnapshot is the data of a request scope at one point in time
+type scopeSnapshot struct {
+	tags     map[string]interface{}
+	contexts map[string]interface{}
+	extra    map[string]interface{}
+}
+
+// snapshotScope captures the tags, contexts and extras of scope
+func snapshotScope(scope *sentry.Scope) scopeSnapshot {
+	// Scope data can only be read by applying it to an event; a transaction
+	// keeps the request processors from attaching anything
+	event := scope.ApplyToEvent(&sentry.Event{Type: "transaction"}, nil, nil)
+	if event == nil {
+		return scopeSnapshot{}
+	}
+	return snapshotEvent(event)
+}
+
+// snapshotEvent captures the tags, contexts and extras of an event
+func snapshotEvent(event *sentry.Event) scopeSnapshot {
+	snapshot := scopeSnapshot{
+		tags:     make(map[string]interface{}, len(event.Tags)),
+		contexts: make(map[string]interface{}, len(event.Contexts)),
+		extra:    event.Extra,
+	}
+	for key, value := range event.Tags {
+		snapshot.tags[key] = value
+	}
+	for key, value := range event.Contexts {
+		// The trace context changes with every span
+		if key != "trace" {
+			snapshot.contexts[key] = value
+		}
+	}
+	return snapshot
+}
+
+// scopeDiff lists what one layer changed on the scope. Tags are listed with
+// their values; contexts and extras by key only, as they may be large.
+type scopeDiff struct {
+	TagsAdded       map[string]string `json:"tags_added,omitempty"`
+	TagsChanged     map[string]string `json:"tags_changed,omitempty"`
+	TagsRemoved     []string          `json:"tags_removed,omitempty"`
+	ContextsAdded   []string          `json:"contexts_added,omitempty"`
+	ContextsChanged []string          `json:"contexts_changed,omitempty"`
+	ContextsRemoved []string          `json:"contexts_removed,omitempty"`
+	ExtraAdded      []string          `json:"extra_added,omitempty"`
+	ExtraChanged    []string          `json:"extra_changed,omitempty"`
+	ExtraRemoved    []string          `json:"extra_removed,omitempty"`
+}
+
+// diffScopes returns what changed from before to after
+func diffScopes(before, after scopeSnapshot) scopeDiff {
+	var diff scopeDiff
+
+	added, changed, removed := diffKeys(before.tags, after.tags)
+	if len(added) > 0 {
+		diff.TagsAdded = make(map[string]string, len(added))
+		for _, key := range added {
+			diff.TagsAdded[key] = fmt.Sprint(after.tags[key])
+		}
+	}
+	if len(changed) > 0 {
+		diff.TagsChanged = make(map[string]string, len(changed))
+		for _, key := range changed {
+			diff.TagsChanged[key] = fmt.Sprintf("%v -> %v", before.tags[key], after.tags[key])
+		}
+	}
+	diff.TagsRemoved = removed
+
+	diff.ContextsAdded, diff.ContextsChanged, diff.ContextsRemoved = diffKeys(before.contexts, after.contexts)
+	diff.ExtraAdded, diff.ExtraChanged, diff.ExtraRemoved = diffKeys(before.extra, after.extra)
+	return diff
+}
+
+// diffKeys returns the sorted keys added, changed and removed from before
+// to after
+func diffKeys(before, after map[string]interface{}) (added, changed, removed []string) {
+	for key, value := range after {
+		previous, ok := before[key]
+		switch {
+		case !ok:
+			added = append(added, key)
+		case !reflect.DeepEqual(previous, value):
+			changed = append(changed, key)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(changed)
+	sort.Strings(removed)
+	return added, changed, removed
+}
+
+// scopeDiffReport attributes the scope data of an event to the layer that
+// set it: Init (root scope), the middleware (request hub setup) and the
+// request (handlers, later middleware and WithScope)
+type scopeDiffReport struct {
+	Init       scopeDiff `json:"init"`
+	Middleware scopeDiff `json:"middleware"`
+	Request    scopeDiff `json:"request"`
+}
+
+// scopeDiffProcessor attaches "scope-diff.json" to error events, comparing
+// the root scope and the request scope at middleware entry with the scope
+// at capture time
+func scopeDiffProcessor(root, entry scopeSnapshot) sentry.EventProcessor {
+	return func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+		if event.Type != "" {
+			return event
+		}
+
+		report := scopeDiffReport{
+			Init:       diffScopes(scopeSnapshot{}, root),
+			Middleware: diffScopes(root, entry),
+			Request:    diffScopes(entry, snapshotEvent(event)),
+		}
+
+		// Keep the "->" of changed tags readable
+		var payload bytes.Buffer
+		encoder := json.NewEncoder(&payload)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return event
+		}
+		event.Attachments = append(event.Attachments, &sentry.Attachment{
+			Filename:    "scope-diff.json",
+			ContentType: "application/json",
+			Payload:     payload.Bytes(),
+		})
+		return event
+	}
+}
@@ -0,0 +1,98 @@
+package sentrykit
+
+import (
+	"runtime/debug"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// modulePath is the import path of the kit, as reported in sdk.packages
+const modulePath = "github.com/purwadarozatun/go-sentry-fiber-3"
+
+// SDKConfig overrides how events identify the SDK that sent them. The name
+// and version also make up the User-Agent of requests to Sentry.
+type SDKConfig struct {
+	// Name replaces the SDK name (default: "sentry.go")
+	Name string
+
+	// Version replaces the SDK version (default: the sentry-go version)
+	Version string
+
+	// Integration is the entry listed in sdk.integrations for the kit, e.g.
+	// an internal wrapper's "platform-kit@2.3.1" (default: "sentrykit")
+	Integration string
+}
+
+// sdkIntegration lists the kit in sdk.integrations and applies SDKConfig.
+// sentry-go fills in the SDK info of every event, transactions included,
+// before the client processors run.
+type sdkIntegration struct {
+	config  SDKConfig
+	version string
+}
+
+// newSDKIntegration returns the kit integration for a client
+func newSDKIntegration(config SDKConfig) *sdkIntegration {
+	if config.Integration == "" {
+		config.Integration = "sentrykit"
+	}
+	return &sdkIntegration{config: config, version: moduleVersion()}
+}
+
+// Name is the entry listed in sdk.integrations
+func (i *sdkIntegration) Name() string {
+	return i.config.Integration
+}
+
+// SetupOnce adds the processor overriding the SDK info
+func (i *sdkIntegration) SetupOnce(client *sentry.Client) {
+	client.AddEventProcessor(i.apply)
+}
+
+// apply overrides the SDK name and version and lists the kit's module in
+// sdk.packages
+func (i *sdkIntegration) apply(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+	if i.config.Name != "" {
+		event.Sdk.Name = i.config.Name
+	}
+	if i.config.Version != "" {
+		event.Sdk.Version = i.config.Version
+	}
+	if i.version != "" {
+		event.Sdk.Packages = append(event.Sdk.Packages, sentry.SdkPackage{
+			Name:    modulePath,
+			Version: i.version,
+		})
+	}
+	return event
+}
+
+// moduleVersion returns the version of the kit the binary was built with,
+// empty when unknown (e.g. in its own tests or a replaced module)
+func moduleVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != modulePath {
+			continue
+		}
+		version := dep.Version
+		if dep.Replace != nil {
+			version = dep.Replace.Version
+		}
+		if version == "(devel)" {
+			return ""
+		}
+		return version
+	}
+	return ""
+}
+
+// withSDKIntegration appends the kit integration to sentry-go's defaults
+func withSDKIntegration(config SDKConfig) func([]sentry.Integration) []sentry.Integration {
+	return func(integrations []sentry.Integration) []sentry.Integration {
+		return append(integrations, newSDKIntegration(config))
+	}
+}
@@ -0,0 +1,134 @@
+package sentrykit
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// selfReportInterval is the minimum time between self-reports per component
+const selfReportInterval = time.Minute
+
+// selfMonitor tracks the kit's own failures
+var selfMonitor struct {
+	mu         sync.Mutex
+	logger     *log.Logger
+	report     bool
+	counts     map[string]uint64
+	lastReport map[string]time.Time
+}
+
+// configureSelfMonitor sets where internal failures are logged and whether
+// they are self-reported
+func configureSelfMonitor(logger *log.Logger, report bool) {
+	selfMonitor.mu.Lock()
+	defer selfMonitor.mu.Unlock()
+	selfMonitor.logger = logger
+	selfMonitor.report = report
+}
+
+// selfMonitoring reports whether self-monitoring is configured
+func selfMonitoring() bool {
+	selfMonitor.mu.Lock()
+	defer selfMonitor.mu.Unlock()
+	return selfMonitor.logger != nil
+}
+
+// checkMarshal records an event that can't be encoded as JSON, which
+// sentry-go then sends stripped of breadcrumbs, contexts and extras, or not
+// at all. It only runs with self-monitoring, as it encodes every event once
+// more.
+func checkMarshal(event *sentry.Event) {
+	if !selfMonitoring() {
+		return
+	}
+	if _, err := json.Marshal(event); err != nil {
+		reportInternal("marshal", fmt.Sprintf("event %s can't be encoded: %s", event.EventID, err))
+	}
+}
+
+// InternalErrors returns the number of internal failures per component
+// (transport, marshal, queue_full, rate_limited, processor, dsn) since start
+func InternalErrors() map[string]uint64 {
+	selfMonitor.mu.Lock()
+	defer selfMonitor.mu.Unlock()
+
+	counts := make(map[string]uint64, len(selfMonitor.counts))
+	for component, count := range selfMonitor.counts {
+		counts[component] = count
+	}
+	return counts
+}
+
+// reportInternal records an internal failure, logs it and, if enabled,
+// self-reports it at most once per component per selfReportInterval
+func reportInternal(component, message string) {
+	selfMonitor.mu.Lock()
+	if selfMonitor.counts == nil {
+		selfMonitor.counts = make(map[string]uint64)
+		selfMonitor.lastReport = make(map[string]time.Time)
+	}
+	selfMonitor.counts[component]++
+	logger := selfMonitor.logger
+
+	shouldReport := false
+	if selfMonitor.report && since(selfMonitor.lastReport[component]) >= selfReportInterval {
+		selfMonitor.lastReport[component] = now()
+		shouldReport = true
+	}
+	selfMonitor.mu.Unlock()
+
+	if logger != nil {
+		logger.Printf("sentrykit: %s failure: %s", component, message)
+	}
+
+	// Transport failures are not self-reported: the report would most
+	// likely fail the same way and feed back into the monitor
+	if shouldReport && component != "transport" && component != "queue_full" {
+		hub := sentry.NewHub(sentry.CurrentHub().Client(), sentry.NewScope())
+		hub.WithScope(func(scope *sentry.Scope) {
+			scope.SetLevel(sentry.LevelWarning)
+			scope.SetTag("sentrykit_internal", component)
+			scope.SetFingerprint([]string{"sentrykit-internal", component})
+			hub.CaptureMessage(fmt.Sprintf("sentrykit internal %s failure: %s", component, message))
+		})
+	}
+}
+
+// monitoringRoundTripper observes the result of every request delivering
+// events to Sentry: network errors and error responses are transport
+// failures, 429 responses rate limiting
+type monitoringRoundTripper struct {
+	base http.RoundTripper
+}
+
+// RoundTrip sends the request and records a failed delivery
+func (t *monitoringRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	switch {
+	case err != nil:
+		reportInternal("transport", err.Error())
+	case resp.StatusCode == http.StatusTooManyRequests:
+		reportInternal("rate_limited", "delivery rate limited by Sentry")
+	case resp.StatusCode >= http.StatusBadRequest:
+		reportInternal("transport", fmt.Sprintf("delivery failed with status %d", resp.StatusCode))
+	}
+	return resp, err
+}
+
+// withSelfMonitor returns a copy of client whose requests are observed by
+// the self-monitor
+func withSelfMonitor(client *http.Client) *http.Client {
+	monitored := *client
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	monitored.Transport = &monitoringRoundTripper{base: base}
+	return &monitored
+}
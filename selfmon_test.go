@@ -0,0 +1,82 @@
+package sentrykit
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+func TestSelfMonitorObservesDeliveryResults(t *testing.T) {
+	var status int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	options, _, err := clientOptions(Config{
+		DSN:         "http://key@" + strings.TrimPrefix(server.URL, "http://") + "/1",
+		SelfMonitor: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if options.Debug {
+		t.Error("self-monitoring turned on sentry-go debug output")
+	}
+	client, err := sentry.NewClient(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	hub := sentry.NewHub(client, sentry.NewScope())
+
+	before := InternalErrors()
+	// sentry-go holds back events after a 429, so it goes last
+	for _, status = range []int{http.StatusInternalServerError, http.StatusTooManyRequests} {
+		hub.CaptureMessage("delivery check")
+		if !hub.Flush(time.Second) {
+			t.Fatal("event not delivered")
+		}
+	}
+
+	after := InternalErrors()
+	if after["rate_limited"] != before["rate_limited"]+1 {
+		t.Errorf("rate_limited = %d, want %d", after["rate_limited"], before["rate_limited"]+1)
+	}
+	if after["transport"] != before["transport"]+1 {
+		t.Errorf("transport = %d, want %d", after["transport"], before["transport"]+1)
+	}
+}
+
+func TestSelfMonitorRecordsMarshalFailures(t *testing.T) {
+	selfMonitor.mu.Lock()
+	logger, report := selfMonitor.logger, selfMonitor.report
+	selfMonitor.mu.Unlock()
+	configureSelfMonitor(log.New(io.Discard, "", 0), false)
+	t.Cleanup(func() { configureSelfMonitor(logger, report) })
+
+	transport := &sentry.MockTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:        testDSN,
+		Transport:  transport,
+		BeforeSend: beforeSend(nil, nil),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hub := sentry.NewHub(client, sentry.NewScope())
+
+	before := InternalErrors()
+	hub.Scope().SetExtra("callback", func() {})
+	hub.CaptureMessage("unencodable")
+
+	if got := InternalErrors()["marshal"]; got != before["marshal"]+1 {
+		t.Errorf("marshal = %d, want %d", got, before["marshal"]+1)
+	}
+}
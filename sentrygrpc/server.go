@@ -0,0 +1,254 @@
+// Package sentrygrpc provides gRPC server interceptors built on the kit's
+// middleware configuration: a hub per RPC, trace continuation from incoming
+// metadata, panic recovery and capture of server-side failures. Events go
+// through the same processors and scrubbers as the HTTP middleware.
+package sentrygrpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	sentrykit "github.com/purwadarozatun/go-sentry-fiber-3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor creates a Sentry interceptor for unary RPCs.
+// The RPC hub is available via sentry.GetHubFromContext(ctx).
+func UnaryServerInterceptor(config ...sentrykit.MiddlewareConfig) grpc.UnaryServerInterceptor {
+	cfg := interceptorConfig(config...)
+	captureHeader := cfg.HeaderFilter()
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		rpc := startRPC(ctx, cfg, captureHeader, info.FullMethod)
+		defer rpc.transaction.Finish()
+
+		defer func() {
+			if r := recover(); r != nil {
+				err = rpc.recoverPanic(r)
+			}
+		}()
+
+		resp, err = handler(rpc.transaction.Context(), req)
+		rpc.finish(err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor creates a Sentry interceptor for streaming RPCs.
+// The RPC hub is available via sentry.GetHubFromContext(stream.Context()).
+func StreamServerInterceptor(config ...sentrykit.MiddlewareConfig) grpc.StreamServerInterceptor {
+	cfg := interceptorConfig(config...)
+	captureHeader := cfg.HeaderFilter()
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		rpc := startRPC(ss.Context(), cfg, captureHeader, info.FullMethod)
+		defer rpc.transaction.Finish()
+
+		defer func() {
+			if r := recover(); r != nil {
+				err = rpc.recoverPanic(r)
+			}
+		}()
+
+		err = handler(srv, &serverStream{ServerStream: ss, ctx: rpc.transaction.Context()})
+		rpc.finish(err)
+		return err
+	}
+}
+
+// interceptorConfig resolves the optional config like the HTTP middleware
+func interceptorConfig(config ...sentrykit.MiddlewareConfig) sentrykit.MiddlewareConfig {
+	cfg := sentrykit.DefaultMiddlewareConfig()
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if err := cfg.Validate(); err != nil {
+		panic("sentrygrpc: invalid middleware config: " + err.Error())
+	}
+	return cfg
+}
+
+// rpcState tracks the hub and transaction of a single RPC
+type rpcState struct {
+	cfg         sentrykit.MiddlewareConfig
+	hub         *sentry.Hub
+	transaction *sentry.Span
+}
+
+// startRPC clones a hub for the RPC, adds the RPC context and starts the
+// transaction, continuing an incoming trace from metadata
+func startRPC(ctx context.Context, cfg sentrykit.MiddlewareConfig, captureHeader func(key string) bool, fullMethod string) *rpcState {
+	base := sentry.CurrentHub()
+	if cfg.Client != nil {
+		base = cfg.Client.Hub()
+	}
+	hub := base.Clone()
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	service, method := splitMethod(fullMethod)
+
+	captured := make(map[string]string, len(md))
+	for key, values := range md {
+		// Skip sensitive or non-allowlisted metadata
+		if len(values) > 0 && captureHeader(key) {
+			captured[key] = strings.Join(values, ", ")
+		}
+	}
+
+	rpcContext := map[string]interface{}{
+		"method":   fullMethod,
+		"metadata": captured,
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		rpcContext["peer"] = p.Addr.String()
+	}
+
+	hub.Scope().SetContext("grpc", rpcContext)
+	hub.Scope().SetTag("grpc.service", service)
+	hub.Scope().SetTag("grpc.method", method)
+
+	// Map configured metadata keys to tags, like HeaderTags for HTTP
+	for header, tag := range cfg.HeaderTags {
+		if values := md.Get(header); len(values) > 0 && values[0] != "" {
+			hub.Scope().SetTag(tag, values[0])
+		}
+	}
+
+	op := cfg.TransactionOp
+	if op == "" {
+		op = "grpc.server"
+	}
+
+	transaction := sentry.StartTransaction(
+		sentry.SetHubOnContext(ctx, hub),
+		fullMethod,
+		sentry.WithOpName(op),
+		sentry.WithTransactionSource(sentry.SourceRoute),
+		sentry.ContinueFromHeaders(first(md, sentry.SentryTraceHeader), first(md, sentry.SentryBaggageHeader)),
+	)
+
+	return &rpcState{cfg: cfg, hub: hub, transaction: transaction}
+}
+
+// finish records the RPC outcome and captures server-side failures
+func (r *rpcState) finish(err error) {
+	code := status.Code(err)
+	r.transaction.Status = spanStatus(code)
+	r.transaction.SetData("rpc.grpc.status_code", int(code))
+
+	if err != nil && isServerError(code) {
+		r.hub.Scope().SetTag("grpc.code", code.String())
+		r.hub.CaptureException(err)
+	}
+
+	if r.cfg.WaitForDelivery {
+		r.flush()
+	}
+}
+
+// recoverPanic reports a panic and converts it into an Internal error,
+// re-panicking instead when Repanic is set
+func (r *rpcState) recoverPanic(value interface{}) error {
+	r.transaction.Status = sentry.SpanStatusInternalError
+	r.hub.RecoverWithContext(r.transaction.Context(), value)
+
+	if r.cfg.WaitForDelivery {
+		r.flush()
+	}
+
+	if r.cfg.Repanic {
+		panic(value)
+	}
+	return status.Error(codes.Internal, "internal error")
+}
+
+// flush waits for delivery, bounded by the configured timeout
+func (r *rpcState) flush() {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.Timeout)
+	defer cancel()
+	r.hub.FlushWithContext(ctx)
+}
+
+// serverStream overrides the stream context with the RPC context
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context carrying the RPC hub and transaction
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// splitMethod splits "/package.Service/Method" into service and method
+func splitMethod(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndexByte(fullMethod, '/'); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "", fullMethod
+}
+
+// first returns the first metadata value for key, or ""
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// isServerError reports whether a status code is a server-side failure,
+// the gRPC equivalent of an HTTP 5xx
+func isServerError(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.Internal, codes.Unimplemented, codes.Unavailable, codes.DataLoss, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// spanStatus maps a gRPC status code to a span status
+func spanStatus(code codes.Code) sentry.SpanStatus {
+	switch code {
+	case codes.OK:
+		return sentry.SpanStatusOK
+	case codes.Canceled:
+		return sentry.SpanStatusCanceled
+	case codes.InvalidArgument:
+		return sentry.SpanStatusInvalidArgument
+	case codes.DeadlineExceeded:
+		return sentry.SpanStatusDeadlineExceeded
+	case codes.NotFound:
+		return sentry.SpanStatusNotFound
+	case codes.AlreadyExists:
+		return sentry.SpanStatusAlreadyExists
+	case codes.PermissionDenied:
+		return sentry.SpanStatusPermissionDenied
+	case codes.ResourceExhausted:
+		return sentry.SpanStatusResourceExhausted
+	case codes.FailedPrecondition:
+		return sentry.SpanStatusFailedPrecondition
+	case codes.Aborted:
+		return sentry.SpanStatusAborted
+	case codes.OutOfRange:
+		return sentry.SpanStatusOutOfRange
+	case codes.Unimplemented:
+		return sentry.SpanStatusUnimplemented
+	case codes.Unavailable:
+		return sentry.SpanStatusUnavailable
+	case codes.DataLoss:
+		return sentry.SpanStatusDataLoss
+	case codes.Unauthenticated:
+		return sentry.SpanStatusUnauthenticated
+	case codes.Internal:
+		return sentry.SpanStatusInternalError
+	default:
+		return sentry.SpanStatusUnknown
+	}
+}
@@ -0,0 +1,105 @@
+package sentrykit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/session"
+)
+
+// setSessionContext tags the hub with the hashed session ID, freshness and
+// age. It reads the session ID from the request and the session data from
+// the storage directly: Store.Get would create a session (and a session ID
+// for the application) on every request, and beta Fiber sessions can't be
+// released back to their pool.
+func setSessionContext(c fiber.Ctx, hub *sentry.Hub, store *session.Store, createdKey string) {
+	id := sessionID(c, store.KeyLookup)
+	if id == "" {
+		// The application creates the session, if it uses one
+		hub.Scope().SetTag("session_fresh", "true")
+		hub.Scope().SetContext("session", map[string]interface{}{"fresh": true})
+		return
+	}
+
+	raw, err := store.Storage.Get(id)
+	if err != nil {
+		return
+	}
+
+	// An unknown or expired ID gets replaced by a fresh session
+	fresh := raw == nil
+	idHash := hashValue(id)
+	data := map[string]interface{}{
+		"id_hash": idHash,
+		"fresh":   fresh,
+	}
+
+	if createdKey != "" && !fresh {
+		if createdAt, ok := sessionCreatedAt(sessionValue(raw, createdKey)); ok {
+			data["created_at"] = createdAt.UTC().Format(time.RFC3339)
+			data["age_seconds"] = int64(since(createdAt).Seconds())
+		}
+	}
+
+	hub.Scope().SetTag("session_id", idHash)
+	hub.Scope().SetTag("session_fresh", strconv.FormatBool(fresh))
+	hub.Scope().SetContext("session", data)
+}
+
+// sessionID returns the session ID sent with the request, looked up like
+// Store.Get does: the cookie first, then the header or query parameter
+// named by keyLookup ("<source>:<name>")
+func sessionID(c fiber.Ctx, keyLookup string) string {
+	source, name, ok := strings.Cut(keyLookup, ":")
+	if !ok {
+		return ""
+	}
+
+	if id := c.Cookies(name); id != "" {
+		return strings.Clone(id)
+	}
+	switch session.Source(source) {
+	case session.SourceHeader:
+		return strings.Clone(c.Get(name))
+	case session.SourceURLQuery:
+		return strings.Clone(c.Query(name))
+	}
+	return ""
+}
+
+// sessionValue decodes stored session data, a gob-encoded map, and returns
+// the value under key
+func sessionValue(raw []byte, key string) interface{} {
+	var values map[string]interface{}
+	if err := gob.NewDecoder(bytes.NewReader(raw)).Decode(&values); err != nil {
+		return nil
+	}
+	return values[key]
+}
+
+// sessionCreatedAt converts a stored session creation value to a time
+func sessionCreatedAt(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case int64:
+		return time.Unix(v, 0), true
+	case int:
+		return time.Unix(int64(v), 0), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// hashValue returns a short, stable SHA-256 digest of a sensitive value
+func hashValue(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:8])
+}
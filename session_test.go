@@ -0,0 +1,68 @@
+package sentrykit
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/session"
+)
+
+func TestSessionTagsWithoutCreatingSessions(t *testing.T) {
+	transport := bindTestClient(t)
+	store := session.New()
+
+	app := fiber.New()
+	app.Use(New(MiddlewareConfig{SessionStore: store, SessionCreatedKey: "created_at"}))
+	app.Get("/login", func(c fiber.Ctx) error {
+		sess, err := store.Get(c)
+		if err != nil {
+			return err
+		}
+		sess.Set("created_at", time.Now().Add(-time.Hour).Unix())
+		return sess.Save()
+	})
+	app.Get("/fail", func(c fiber.Ctx) error {
+		return errors.New("failed")
+	})
+
+	// Without a session cookie, the middleware doesn't create one
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/fail", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cookie := resp.Header.Get("Set-Cookie"); cookie != "" {
+		t.Errorf("session created for a request without one: %s", cookie)
+	}
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/login", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cookies := resp.Cookies()
+	if len(cookies) == 0 {
+		t.Fatal("login didn't set the session cookie")
+	}
+
+	req := httptest.NewRequest(fiber.MethodGet, "/fail", nil)
+	req.AddCookie(cookies[0])
+	if _, err := app.Test(req); err != nil {
+		t.Fatal(err)
+	}
+
+	event := waitForEvent(transport, time.Second, func(event *sentry.Event) bool {
+		return len(event.Exception) > 0 && event.Tags["session_id"] != ""
+	})
+	if event == nil {
+		t.Fatal("error with session not captured")
+	}
+	if event.Tags["session_id"] != hashValue(cookies[0].Value) || event.Tags["session_fresh"] != "false" {
+		t.Errorf("session tags = %v", event.Tags)
+	}
+	if age, _ := event.Contexts["session"]["age_seconds"].(int64); age < 3600 {
+		t.Errorf("session context = %v, want an age of an hour", event.Contexts["session"])
+	}
+}
@@ -0,0 +1,52 @@
+package sentrykit
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestSharedHubKeepsRequestOffGlobalScope(t *testing.T) {
+	transport := bindTestClient(t)
+	global := sentry.CurrentHub()
+	t.Cleanup(func() { global.Scope().RemoveTag("shared_hub_test") })
+
+	app := fiber.New()
+	app.Use(New(MiddlewareConfig{SharedHub: true}))
+	app.Get("/", func(c fiber.Ctx) error {
+		hub := sentry.GetHubFromContext(c.UserContext())
+		if hub == nil || hub == global {
+			t.Error("request context carries the global hub")
+			return nil
+		}
+		if global.Scope().GetSpan() != nil {
+			t.Error("request transaction set as span of the global scope")
+		}
+		hub.Scope().SetTag("shared_hub_test", "request")
+		return c.SendString("ok")
+	})
+
+	if _, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	if global.Scope().GetSpan() != nil {
+		t.Error("global scope still holds the request transaction")
+	}
+	if event := global.Scope().ApplyToEvent(&sentry.Event{}, nil, nil); event.Tags["shared_hub_test"] != "" {
+		t.Error("tag set on the request hub leaked onto the global scope")
+	}
+
+	transaction := waitForEvent(transport, time.Second, func(event *sentry.Event) bool {
+		return event.Type == "transaction"
+	})
+	if transaction == nil {
+		t.Fatal("transaction not sent")
+	}
+	if transaction.Tags["shared_hub_test"] != "request" {
+		t.Errorf("transaction tags = %v, want the tag set during the request", transaction.Tags)
+	}
+}
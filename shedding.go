@@ -0,0 +1,163 @@
+package sentrykit
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// LoadSheddingConfig configures adaptive load-shedding of telemetry. The
+// capture rate (errors and transactions) of the previous window decides how
+// much is shed in the current one: transactions are dropped first, then
+// errors are sampled. Zero thresholds disable the corresponding level.
+type LoadSheddingConfig struct {
+	// TransactionThreshold is the number of captures per Window above which
+	// transactions are dropped
+	TransactionThreshold int
+
+	// ErrorThreshold is the number of captures per Window above which error
+	// events are sampled with ErrorSampleRate
+	ErrorThreshold int
+
+	// ErrorSampleRate is the fraction of error events kept while errors are
+	// shed (default: 0.1)
+	ErrorSampleRate float64
+
+	// Window over which captures are counted (default: 10 seconds)
+	Window time.Duration
+}
+
+// SheddingLevel describes how much telemetry is currently being shed
+type SheddingLevel int
+
+const (
+	// SheddingNone keeps all telemetry
+	SheddingNone SheddingLevel = iota
+	// SheddingTransactions drops transactions and keeps error events
+	SheddingTransactions
+	// SheddingErrors drops transactions and samples error events
+	SheddingErrors
+)
+
+// String returns the level name used in stats
+func (l SheddingLevel) String() string {
+	switch l {
+	case SheddingTransactions:
+		return "transactions"
+	case SheddingErrors:
+		return "errors"
+	default:
+		return "none"
+	}
+}
+
+// loadShedder tracks the capture rate in fixed windows and derives the
+// shedding level from the last complete window
+type loadShedder struct {
+	cfg LoadSheddingConfig
+
+	mu          sync.Mutex
+	windowStart time.Time
+	count       int
+
+	level atomic.Int32
+}
+
+// shedder is the load shedder of the client configured by Init, or nil
+// when disabled
+var shedder atomic.Pointer[loadShedder]
+
+// newLoadShedder returns a load shedder for cfg, or nil when no threshold is set
+func newLoadShedder(cfg LoadSheddingConfig) *loadShedder {
+	if cfg.TransactionThreshold <= 0 && cfg.ErrorThreshold <= 0 {
+		return nil
+	}
+	if cfg.ErrorSampleRate <= 0 {
+		cfg.ErrorSampleRate = 0.1
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 10 * time.Second
+	}
+	return &loadShedder{cfg: cfg, windowStart: now()}
+}
+
+// observe counts a capture and returns the shedding level in effect
+func (s *loadShedder) observe() SheddingLevel {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := now()
+	if elapsed := current.Sub(s.windowStart); elapsed >= s.cfg.Window {
+		// An idle gap longer than a window means the rate has dropped
+		count := s.count
+		if elapsed >= 2*s.cfg.Window {
+			count = 0
+		}
+		s.level.Store(int32(s.levelFor(count)))
+		s.windowStart = current
+		s.count = 0
+	}
+	s.count++
+
+	return SheddingLevel(s.level.Load())
+}
+
+// levelFor returns the shedding level for a window's capture count
+func (s *loadShedder) levelFor(count int) SheddingLevel {
+	switch {
+	case s.cfg.ErrorThreshold > 0 && count >= s.cfg.ErrorThreshold:
+		return SheddingErrors
+	case s.cfg.TransactionThreshold > 0 && count >= s.cfg.TransactionThreshold:
+		return SheddingTransactions
+	default:
+		return SheddingNone
+	}
+}
+
+// CurrentSheddingLevel returns how much telemetry the client configured by
+// Init is currently shedding
+func CurrentSheddingLevel() SheddingLevel {
+	return shedder.Load().currentLevel()
+}
+
+// currentLevel returns the shedding level in effect; a nil shedder sheds nothing
+func (s *loadShedder) currentLevel() SheddingLevel {
+	if s == nil {
+		return SheddingNone
+	}
+
+	// Without captures the level isn't re-evaluated; an idle gap longer
+	// than a window means nothing is being shed
+	s.mu.Lock()
+	idle := since(s.windowStart) >= 2*s.cfg.Window
+	s.mu.Unlock()
+	if idle {
+		return SheddingNone
+	}
+	return SheddingLevel(s.level.Load())
+}
+
+// shedError drops sampled-out error events while errors are shed
+func (s *loadShedder) shedError(event *sentry.Event) *sentry.Event {
+	if s == nil {
+		return event
+	}
+	if s.observe() == SheddingErrors && rand.Float64() >= s.cfg.ErrorSampleRate {
+		return nil
+	}
+	return event
+}
+
+// shedTransaction drops transactions while any shedding is active
+func (s *loadShedder) shedTransaction(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+	if s == nil {
+		return event
+	}
+	if s.observe() != SheddingNone {
+		return nil
+	}
+	return event
+}
@@ -0,0 +1,54 @@
+package sentrykit
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// HandleSignals flushes buffered events when the process receives SIGTERM
+// or SIGINT, then lets the signal take its usual course. The kit stops
+// listening and re-raises the signal: without a signal.Notify handler of
+// the app's own the process terminates as it would have without the kit;
+// otherwise the app's handlers receive it again and the app decides when
+// to exit. Call the returned function to uninstall the handler.
+func HandleSignals(timeout time.Duration) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+
+	done := make(chan struct{})
+	var once sync.Once
+
+	go func() {
+		select {
+		case <-done:
+			return
+		case sig := <-signals:
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			FlushCtx(ctx)
+			cancel()
+
+			// Stop restores the default action when no other channel is
+			// registered, which the re-raised signal then triggers
+			signal.Stop(signals)
+			raise(sig)
+		}
+	}()
+
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
+
+// raise sends sig to the current process
+func raise(sig os.Signal) {
+	if process, err := os.FindProcess(os.Getpid()); err == nil {
+		_ = process.Signal(sig)
+	}
+}
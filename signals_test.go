@@ -0,0 +1,64 @@
+//go:build unix
+
+package sentrykit
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestHandleSignalsLeavesShutdownToApp(t *testing.T) {
+	bindTestClient(t)
+
+	// The app's own shutdown handler
+	appSignals := make(chan os.Signal, 2)
+	signal.Notify(appSignals, syscall.SIGTERM)
+	defer signal.Stop(appSignals)
+
+	defer HandleSignals(time.Second)()
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-appSignals:
+	case <-time.After(time.Second):
+		t.Fatal("app handler didn't receive the signal")
+	}
+
+	// The re-raised signal reaches the app's handler too, and the process
+	// keeps running
+	select {
+	case <-appSignals:
+	case <-time.After(time.Second):
+		t.Fatal("signal not re-raised")
+	}
+}
+
+func TestHandleSignalsTerminatesWithoutAppHandler(t *testing.T) {
+	if os.Getenv("SENTRYKIT_SIGNAL_CHILD") == "1" {
+		HandleSignals(time.Second)
+		_ = syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+		time.Sleep(5 * time.Second)
+		os.Exit(0)
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHandleSignalsTerminatesWithoutAppHandler$")
+	cmd.Env = append(os.Environ(), "SENTRYKIT_SIGNAL_CHILD=1")
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("child exited with %v, want termination by SIGTERM", err)
+	}
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() || status.Signal() != syscall.SIGTERM {
+		t.Errorf("child exited with %v, want termination by SIGTERM", err)
+	}
+}
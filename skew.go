@@ -0,0 +1,149 @@
+package sentrykit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// versionSkewCooldown is the minimum time between two skew reports for the
+// same client and server release
+const versionSkewCooldown = 10 * time.Minute
+
+// maxVersionSkewKeys bounds the client/server release pairs tracked for
+// skew report cooldowns
+const maxVersionSkewKeys = 1000
+
+// versionSkewReports holds a cooldown per client/server release pair
+var versionSkewReports struct {
+	cooldowns sync.Map // "client -> server" -> *cooldown
+	keys      atomic.Int32
+}
+
+// releaseVersion is the major and minor version of a release
+type releaseVersion struct {
+	major, minor int
+}
+
+// parseReleaseVersion reads the major and minor version of a release such
+// as "1.4.2", "v1.4" or "my-app@1.4.2+build"
+func parseReleaseVersion(release string) (releaseVersion, bool) {
+	if i := strings.LastIndexByte(release, '@'); i >= 0 {
+		release = release[i+1:]
+	}
+	release = strings.TrimPrefix(release, "v")
+
+	parts := strings.SplitN(release, ".", 3)
+	if len(parts) < 2 {
+		return releaseVersion{}, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return releaseVersion{}, false
+	}
+	minor, err := strconv.Atoi(leadingDigits(parts[1]))
+	if err != nil {
+		return releaseVersion{}, false
+	}
+	return releaseVersion{major: major, minor: minor}, true
+}
+
+// leadingDigits returns the digits at the start of s
+func leadingDigits(s string) string {
+	for i, r := range s {
+		if r < '0' || r > '9' {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// clientRelease returns the caller's release from the configured header
+func (r *requestState) clientRelease() string {
+	if r.cfg.ClientVersionHeader == "" || r.req.Header == nil {
+		return ""
+	}
+	// Copied, since Fiber reuses the header buffers after the request
+	return strings.Clone(r.req.Header(r.cfg.ClientVersionHeader))
+}
+
+// serverRelease returns the release of the client reporting the request
+func (r *requestState) serverRelease() string {
+	if client := r.baseHub().Client(); client != nil {
+		return client.Options().Release
+	}
+	return ""
+}
+
+// versionSkew returns how many minor versions the client release is behind
+// (positive) or ahead of (negative) the server release, and whether the
+// major versions differ. ok is false when either release isn't a version.
+func (r *requestState) versionSkew() (skew int, majorDiffers bool, ok bool) {
+	client, clientOK := parseReleaseVersion(r.clientRelease())
+	server, serverOK := parseReleaseVersion(r.serverRelease())
+	if !clientOK || !serverOK {
+		return 0, false, false
+	}
+	return server.minor - client.minor, server.major != client.major, true
+}
+
+// setReleaseTags tags the hub with the client and server releases
+func (r *requestState) setReleaseTags(hub *sentry.Hub) {
+	client := r.clientRelease()
+	if client == "" {
+		return
+	}
+	hub.Scope().SetTag("client_release", truncateTag(client))
+	if server := r.serverRelease(); server != "" {
+		hub.Scope().SetTag("server_release", server)
+	}
+	if skew, majorDiffers, ok := r.versionSkew(); ok && !majorDiffers {
+		hub.Scope().SetTag("version_skew", strconv.Itoa(skew))
+	}
+}
+
+// reportVersionSkew captures a warning when the client release is more than
+// MaxVersionSkew minor versions away from the server release, or on another
+// major version. Each release pair is reported at most once per cooldown.
+func (r *requestState) reportVersionSkew() {
+	skew, majorDiffers, ok := r.versionSkew()
+	if !ok || (!majorDiffers && skew <= r.cfg.MaxVersionSkew && -skew <= r.cfg.MaxVersionSkew) {
+		return
+	}
+
+	client, server := r.clientRelease(), r.serverRelease()
+	key := client + " -> " + server
+	limiter, found := versionSkewReports.cooldowns.Load(key)
+	if !found {
+		if versionSkewReports.keys.Load() >= maxVersionSkewKeys {
+			return
+		}
+		var loaded bool
+		limiter, loaded = versionSkewReports.cooldowns.LoadOrStore(key, &cooldown{interval: versionSkewCooldown})
+		if !loaded {
+			versionSkewReports.keys.Add(1)
+		}
+	}
+	if !limiter.(*cooldown).allow() {
+		return
+	}
+
+	hub := r.requestHub()
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetLevel(sentry.LevelWarning)
+		scope.SetFingerprint([]string{"version-skew", client, server})
+		scope.SetContext("version_skew", map[string]interface{}{
+			"client_release": client,
+			"server_release": server,
+			"minor_skew":     skew,
+			"major_differs":  majorDiffers,
+			"threshold":      r.cfg.MaxVersionSkew,
+		})
+		hub.CaptureMessage(fmt.Sprintf("Version skew: client %s calling server %s", client, server))
+	})
+}
@@ -0,0 +1,82 @@
+package sentrykit
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// RunMain runs fn, the body of main, and reports it crashing: a panic or a
+// returned error is captured at fatal level and flushed before the process
+// exits, so crashes during startup and config loading, before the Fiber
+// middleware exists, are visible in Sentry. If Init has not run yet, a client
+// is configured from the SENTRY_DSN, SENTRY_RELEASE and SENTRY_ENVIRONMENT
+// environment variables. A returned error is printed to stderr and exits
+// with status 1; a panic is re-raised after the flush.
+//
+//	func main() {
+//	    sentrykit.RunMain(func() error {
+//	        cfg, err := loadConfig()
+//	        if err != nil {
+//	            return err
+//	        }
+//	        ...
+//	    })
+//	}
+func RunMain(fn func() error) {
+	initFromEnvironment()
+
+	defer func() {
+		if value := recover(); value != nil {
+			hub := sentry.CurrentHub()
+			hub.WithScope(func(scope *sentry.Scope) {
+				scope.SetTag("crash_phase", "startup")
+				recoverValue(context.Background(), hub, value)
+			})
+			flushFatal()
+			panic(value)
+		}
+	}()
+
+	if err := fn(); err != nil {
+		CaptureFatal(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// CaptureFatal captures err at fatal level and flushes it, for exiting on
+// errors outside of RunMain (e.g. before log.Fatal). If Init has not run yet,
+// a client is configured from the environment as in RunMain.
+func CaptureFatal(err error) *sentry.EventID {
+	initFromEnvironment()
+
+	var eventID *sentry.EventID
+	sentry.WithScope(func(scope *sentry.Scope) {
+		scope.SetLevel(sentry.LevelFatal)
+		scope.SetTag("crash_phase", "startup")
+		eventID = sentry.CaptureException(err)
+	})
+	flushFatal()
+	return eventID
+}
+
+// initFromEnvironment binds a client configured from SENTRY_* environment
+// variables when none is bound yet
+func initFromEnvironment() {
+	if sentry.CurrentHub().Client() != nil || os.Getenv("SENTRY_DSN") == "" {
+		return
+	}
+	if err := sentry.Init(sentry.ClientOptions{}); err != nil {
+		reportInternal("init", err.Error())
+	}
+}
+
+// flushFatal flushes buffered events before the process exits
+func flushFatal() {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultFlushTimeout)
+	defer cancel()
+	FlushCtx(ctx)
+}
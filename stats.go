@@ -0,0 +1,86 @@
+package sentrykit
+
+import (
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+)
+
+// requestStats accumulates handler response times between stats samples
+var requestStats struct {
+	count   atomic.Int64
+	totalNs atomic.Int64
+	maxNs   atomic.Int64
+}
+
+// recordRequestDuration adds a handled request to the response time stats
+func recordRequestDuration(d time.Duration) {
+	ns := d.Nanoseconds()
+	requestStats.count.Add(1)
+	requestStats.totalNs.Add(ns)
+	for {
+		current := requestStats.maxNs.Load()
+		if ns <= current || requestStats.maxNs.CompareAndSwap(current, ns) {
+			return
+		}
+	}
+}
+
+// StartStatsReporter periodically samples server and runtime stats into the
+// "runtime_stats" context of the global scope, so they accompany every
+// captured event. The numbers are the ones Fiber's monitor middleware shows
+// (process CPU, memory, open connections) plus goroutines, response times
+// and the load shedding level. Call the returned function to stop
+// reporting.
+func StartStatsReporter(app *fiber.App, interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	cpu := newCPUSampler()
+	return startTicker(interval, func() {
+		stats := sampleStats(app, cpu)
+		sentry.ConfigureScope(func(scope *sentry.Scope) {
+			scope.SetContext("runtime_stats", stats)
+		})
+	})
+}
+
+// sampleStats collects a snapshot of server and runtime stats and resets
+// the response time accumulators
+func sampleStats(app *fiber.App, cpu *cpuSampler) map[string]interface{} {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	count := requestStats.count.Swap(0)
+	totalNs := requestStats.totalNs.Swap(0)
+	maxNs := requestStats.maxNs.Swap(0)
+
+	var avgMs float64
+	if count > 0 {
+		avgMs = float64(totalNs) / float64(count) / float64(time.Millisecond)
+	}
+
+	stats := map[string]interface{}{
+		"cpu_percent":      cpu.usage() * 100,
+		"goroutines":       runtime.NumGoroutine(),
+		"heap_alloc_bytes": mem.HeapAlloc,
+		"sys_bytes":        mem.Sys,
+		"num_gc":           mem.NumGC,
+		"requests":         count,
+		"response_avg_ms":  avgMs,
+		"response_max_ms":  float64(maxNs) / float64(time.Millisecond),
+		"shedding_level":   CurrentSheddingLevel().String(),
+		"sampled_at":       now().UTC().Format(time.RFC3339),
+	}
+
+	if server := app.Server(); server != nil {
+		stats["open_connections"] = server.GetOpenConnectionsCount()
+		stats["concurrency"] = server.GetCurrentConcurrency()
+	}
+
+	return stats
+}
@@ -0,0 +1,66 @@
+package sentrykit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+)
+
+// SendTestEvent emits a synthetic event with environment and release info
+// and waits until it is delivered or ctx is done. Use it in deploy smoke
+// tests to verify the DSN and network path.
+func SendTestEvent(ctx context.Context) (sentry.EventID, error) {
+	client := sentry.CurrentHub().Client()
+	if client == nil {
+		return "", errors.New("sentry is not initialized")
+	}
+
+	options := client.Options()
+	hub := sentry.NewHub(client, sentry.NewScope())
+
+	var eventID *sentry.EventID
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetLevel(sentry.LevelInfo)
+		scope.SetTag("sentrykit_test", "true")
+		scope.SetContext("test_event", map[string]interface{}{
+			"environment": options.Environment,
+			"release":     options.Release,
+			"server_name": options.ServerName,
+			"sent_at":     now().UTC().Format(time.RFC3339),
+		})
+		eventID = hub.CaptureMessage("sentrykit test event")
+	})
+
+	if eventID == nil {
+		return "", errors.New("test event was dropped before sending")
+	}
+
+	if !hub.FlushWithContext(ctx) {
+		return *eventID, fmt.Errorf("test event %s was not delivered: %w", *eventID, ctx.Err())
+	}
+
+	return *eventID, nil
+}
+
+// TestEventHandler returns a handler that sends a test event and responds
+// with its ID, for mounting on an internal route such as /debug/sentry/test
+func TestEventHandler(timeout time.Duration) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.UserContext(), timeout)
+		defer cancel()
+
+		eventID, err := SendTestEvent(ctx)
+		if err != nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"event_id": eventID,
+				"error":    err.Error(),
+			})
+		}
+
+		return c.JSON(fiber.Map{"event_id": eventID})
+	}
+}
@@ -0,0 +1,127 @@
+package sentrykit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/timeout"
+)
+
+// timeoutKey holds the deadline configured by Timeout
+const timeoutKey = "sentry_timeout"
+
+// Timeout wraps Fiber's timeout middleware and records the configured
+// deadline, so timeout events report it alongside the elapsed time and
+// events captured during the handler carry the budget left (see
+// deadlineProcessor). Arguments are the same as timeout.New.
+//
+//	app.Get("/report", sentrykit.Timeout(reportHandler, 5*time.Second))
+func Timeout(h fiber.Handler, t time.Duration, tErrs ...error) fiber.Handler {
+	handler := timeout.New(h, t, tErrs...)
+	return func(c fiber.Ctx) error {
+		c.Locals(timeoutKey, t)
+		if state, ok := c.Locals(requestStateKey).(*requestState); ok {
+			state.setDeadline(t, now().Add(t))
+		}
+		return handler(c)
+	}
+}
+
+// isTimeoutError reports whether err comes from a request deadline: Fiber's
+// timeout middleware (408) or an expired context
+func isTimeoutError(err error) bool {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) && fiberErr.Code == fiber.StatusRequestTimeout {
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// fiberDeadline returns the configured timeout and the deadline of the
+// request context, when known. The timeout middleware leaves its context on
+// the request after the handler returns.
+func fiberDeadline(c fiber.Ctx) (time.Duration, time.Time) {
+	t, _ := c.Locals(timeoutKey).(time.Duration)
+	deadline, _ := c.UserContext().Deadline()
+	return t, deadline
+}
+
+// setDeadline records the request timeout and deadline; zero values keep
+// the ones known before
+func (r *requestState) setDeadline(timeout time.Duration, deadline time.Time) {
+	r.deadlineMu.Lock()
+	defer r.deadlineMu.Unlock()
+	if timeout > 0 {
+		r.timeout = timeout
+	}
+	if !deadline.IsZero() {
+		r.deadline = deadline
+	}
+}
+
+// requestDeadline returns the handler timeout and deadline known so far:
+// the incoming context's deadline, then the one set by Timeout, then the
+// one of the request context once the handler returned
+func (r *requestState) requestDeadline() (time.Duration, time.Time) {
+	r.deadlineMu.Lock()
+	defer r.deadlineMu.Unlock()
+	return r.timeout, r.deadline
+}
+
+// deadlineProcessor tags error events with the deadline budget left at
+// capture time ("deadline_remaining", bucketed) and the configured handler
+// timeout ("handler_timeout"), with the exact values in a "deadline"
+// context, so deadline-driven failures stand out
+func deadlineProcessor(r *requestState) sentry.EventProcessor {
+	return func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+		if event.Type != "" {
+			return event
+		}
+		timeout, deadline := r.requestDeadline()
+		if timeout <= 0 && deadline.IsZero() {
+			return event
+		}
+
+		if event.Tags == nil {
+			event.Tags = make(map[string]string)
+		}
+		if event.Contexts == nil {
+			event.Contexts = make(map[string]sentry.Context)
+		}
+		data := sentry.Context{}
+		if timeout > 0 {
+			event.Tags["handler_timeout"] = timeout.String()
+			data["timeout_ms"] = milliseconds(timeout)
+		}
+		if !deadline.IsZero() {
+			remaining := deadline.Sub(now())
+			event.Tags["deadline_remaining"] = deadlineBucket(remaining)
+			data["deadline"] = deadline.UTC().Format(time.RFC3339Nano)
+			data["remaining_ms"] = milliseconds(remaining)
+			if timeout > 0 {
+				data["budget_used"] = 1 - float64(remaining)/float64(timeout)
+			}
+		}
+		event.Contexts["deadline"] = data
+		return event
+	}
+}
+
+// deadlineBucket groups the remaining deadline budget into a tag value
+func deadlineBucket(remaining time.Duration) string {
+	switch {
+	case remaining <= 0:
+		return "exceeded"
+	case remaining < 100*time.Millisecond:
+		return "<100ms"
+	case remaining < time.Second:
+		return "<1s"
+	case remaining < 10*time.Second:
+		return "<10s"
+	default:
+		return ">=10s"
+	}
+}
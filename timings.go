@@ -0,0 +1,100 @@
+package sentrykit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+)
+
+// handlerTimingsKey holds the per-request handler timings
+const handlerTimingsKey = "sentry_handler_timings"
+
+// handlerTimings collects exclusive durations of wrapped handlers for one request
+type handlerTimings struct {
+	mu      sync.Mutex
+	order   []string
+	elapsed map[string]time.Duration
+
+	// stack tracks nested handlers; each frame accumulates the time spent
+	// in its nested wrapped handlers so it can be excluded
+	stack []time.Duration
+}
+
+// push starts timing a nested handler
+func (t *handlerTimings) push() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stack = append(t.stack, 0)
+}
+
+// pop records a finished handler's exclusive time and adds its total time
+// to the enclosing handler's nested time
+func (t *handlerTimings) pop(name string, total time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	nested := t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+	if len(t.stack) > 0 {
+		t.stack[len(t.stack)-1] += total
+	}
+
+	if _, ok := t.elapsed[name]; !ok {
+		t.order = append(t.order, name)
+	}
+	t.elapsed[name] += total - nested
+}
+
+// context returns the timings as event context data in milliseconds
+func (t *handlerTimings) context() map[string]interface{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	data := make(map[string]interface{}, len(t.order)+1)
+	for _, name := range t.order {
+		data[name+"_ms"] = float64(t.elapsed[name]) / float64(time.Millisecond)
+	}
+	data["order"] = append([]string(nil), t.order...)
+	return data
+}
+
+// HandlerSpan wraps a middleware or handler in a child span of the request
+// transaction and records its own duration (excluding other wrapped handlers
+// further down the chain). When the middleware captures a server error, the
+// breakdown is attached as the "timings" context.
+//
+//	app.Use(sentrykit.HandlerSpan("auth", authMiddleware))
+func HandlerSpan(name string, handler fiber.Handler) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		timings, ok := c.Locals(handlerTimingsKey).(*handlerTimings)
+		if !ok {
+			timings = &handlerTimings{elapsed: make(map[string]time.Duration)}
+			c.Locals(handlerTimingsKey, timings)
+		}
+
+		parent := c.UserContext()
+		span := sentry.StartSpan(parent, "middleware.handler", sentry.WithDescription(name))
+		c.SetUserContext(span.Context())
+
+		start := now()
+		timings.push()
+		defer func() {
+			timings.pop(name, since(start))
+			c.SetUserContext(parent)
+			span.Finish()
+		}()
+
+		err := handler(c)
+		span.Status = spanStatusFromError(err)
+		return err
+	}
+}
+
+// setHandlerTimings attaches the recorded handler timings to the hub
+func setHandlerTimings(c fiber.Ctx, hub *sentry.Hub) {
+	if timings, ok := c.Locals(handlerTimingsKey).(*handlerTimings); ok {
+		hub.Scope().SetContext("timings", timings.context())
+	}
+}
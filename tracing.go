@@ -0,0 +1,91 @@
+package sentrykit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+)
+
+// WithTransaction starts a transaction, runs fn with the transaction context,
+// sets the transaction status from the returned error and finishes it.
+// Use it for work that isn't an HTTP request (CLI commands, background jobs).
+func WithTransaction(ctx context.Context, name, op string, fn func(ctx context.Context) error) error {
+	if !sentry.HasHubOnContext(ctx) {
+		ctx = sentry.SetHubOnContext(ctx, sentry.CurrentHub().Clone())
+	}
+
+	transaction := sentry.StartTransaction(ctx, name,
+		sentry.WithOpName(op),
+		sentry.WithTransactionSource(sentry.SourceTask),
+	)
+	defer transaction.Finish()
+
+	err := fn(transaction.Context())
+	transaction.Status = spanStatusFromError(err)
+
+	return err
+}
+
+// spanStatusFromError maps an error returned by traced code to a span status
+func spanStatusFromError(err error) sentry.SpanStatus {
+	switch {
+	case err == nil:
+		return sentry.SpanStatusOK
+	case errors.Is(err, context.DeadlineExceeded):
+		return sentry.SpanStatusDeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return sentry.SpanStatusCanceled
+	default:
+		return sentry.SpanStatusInternalError
+	}
+}
+
+// Measure runs fn inside a child span of the request transaction.
+// The span status is set from the returned error, and a failure also
+// records an error breadcrumb on the request hub.
+func Measure(c fiber.Ctx, op, desc string, fn func() error) error {
+	parent := c.UserContext()
+	span := sentry.StartSpan(parent, op, sentry.WithDescription(desc))
+	defer span.Finish()
+
+	// Nest spans started inside fn under this one
+	c.SetUserContext(span.Context())
+	defer c.SetUserContext(parent)
+
+	err := fn()
+	span.Status = spanStatusFromError(err)
+
+	if err != nil {
+		GetHubFromContext(c).AddBreadcrumb(&sentry.Breadcrumb{
+			Message:  fmt.Sprintf("%s failed", desc),
+			Category: op,
+			Data: map[string]interface{}{
+				"error": err.Error(),
+			},
+			Level: sentry.LevelError,
+		}, nil)
+	}
+
+	return err
+}
+
+// IsSampled reports whether the request's trace is being recorded, e.g. to
+// collect expensive diagnostics only when they end up in a transaction.
+// It is false outside the middleware.
+func IsSampled(c fiber.Ctx) bool {
+	span := sentry.SpanFromContext(c.UserContext())
+	return span != nil && span.Sampled.Bool()
+}
+
+// TraceID returns the trace ID of the request, sampled or not, or "" outside
+// the middleware
+func TraceID(c fiber.Ctx) string {
+	span := sentry.SpanFromContext(c.UserContext())
+	if span == nil {
+		return ""
+	}
+	return span.TraceID.String()
+}
@@ -0,0 +1,339 @@
+package sentrykit
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression algorithms for TransportConfig.Compression
+const (
+	CompressionNone = ""
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// TransportConfig tunes the HTTP transport used to deliver events
+type TransportConfig struct {
+	// Compression of envelope bodies: CompressionGzip, CompressionZstd or
+	// CompressionNone (default). zstd needs a Sentry/Relay version that
+	// accepts it.
+	Compression string
+
+	// MaxIdleConns and MaxIdleConnsPerHost size the idle connection pool
+	// (default: http.DefaultTransport's values)
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+
+	// MaxConnsPerHost limits concurrent connections to Sentry (0 = no limit)
+	MaxConnsPerHost int
+
+	// RequestTimeout bounds each delivery request (0 = no timeout)
+	RequestTimeout time.Duration
+
+	// IdleConnTimeout closes idle connections after this long
+	// (default: http.DefaultTransport's value)
+	IdleConnTimeout time.Duration
+
+	// DisableKeepAlives opens a new connection per request
+	DisableKeepAlives bool
+
+	// Endpoint sends envelopes to this base URL instead of the DSN host,
+	// e.g. "http://localhost:3000" for a Relay sidecar or
+	// "https://ingest.internal/sentry" for a self-hosted ingest behind a path
+	// prefix. The DSN still identifies the project.
+	Endpoint string
+
+	// TLSClientCert and TLSClientKey are PEM files of a client certificate
+	// presented to Sentry/Relay (mutual TLS)
+	TLSClientCert string
+	TLSClientKey  string
+
+	// TLSRootCA is a PEM file of CAs trusted for the Sentry/Relay server,
+	// added to the system pool, e.g. for a self-hosted instance
+	TLSRootCA string
+
+	// InsecureSkipVerify disables server certificate verification. Only
+	// allowed with Config.DevMode.
+	InsecureSkipVerify bool
+
+	// UnixSocket delivers envelopes over a Unix domain socket, e.g. to a
+	// Relay sidecar. Requests use plain HTTP unless Endpoint says otherwise.
+	UnixSocket string
+
+	// Failover lists ingest endpoints tried in priority order when the
+	// primary one (Endpoint, UnixSocket or the DSN host) fails with a
+	// network error or 5xx, e.g. another region or a direct route around a
+	// Relay sidecar. Entries are base URLs like Endpoint, or DSNs of another
+	// project to report to.
+	Failover []string
+
+	// FailoverCooldown is how long a failed endpoint is skipped before the
+	// next request probes it again (default: 30s)
+	FailoverCooldown time.Duration
+
+	// QueueSize is the number of events waiting to be sent before further
+	// ones are dropped as queue_full (default: 30)
+	QueueSize int
+}
+
+// isZero reports whether no transport option is set
+func (cfg TransportConfig) isZero() bool {
+	return reflect.ValueOf(cfg).IsZero()
+}
+
+// validate reports invalid transport options
+func (cfg TransportConfig) validate() error {
+	switch cfg.Compression {
+	case CompressionNone, CompressionGzip, CompressionZstd:
+	default:
+		return fmt.Errorf("Transport.Compression must be %q, %q or empty, got %q", CompressionGzip, CompressionZstd, cfg.Compression)
+	}
+	if cfg.Endpoint != "" {
+		u, err := url.Parse(cfg.Endpoint)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("Transport.Endpoint must be an absolute URL like http://localhost:3000, got %q", cfg.Endpoint)
+		}
+	}
+	for _, raw := range cfg.Failover {
+		if _, err := parseFailoverEndpoint(raw); err != nil {
+			return err
+		}
+	}
+	if (cfg.TLSClientCert == "") != (cfg.TLSClientKey == "") {
+		return fmt.Errorf("Transport.TLSClientCert and TLSClientKey must be set together")
+	}
+	if cfg.MaxIdleConns < 0 || cfg.MaxIdleConnsPerHost < 0 || cfg.MaxConnsPerHost < 0 || cfg.RequestTimeout < 0 || cfg.IdleConnTimeout < 0 || cfg.FailoverCooldown < 0 || cfg.QueueSize < 0 {
+		return fmt.Errorf("Transport settings must not be negative")
+	}
+	return nil
+}
+
+// httpClient builds the HTTP client for delivering events
+func (cfg TransportConfig) httpClient() (*http.Client, error) {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+
+	tlsConfig, err := cfg.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	base.TLSClientConfig = tlsConfig
+
+	if cfg.MaxIdleConns > 0 {
+		base.MaxIdleConns = cfg.MaxIdleConns
+	}
+	if cfg.MaxIdleConnsPerHost > 0 {
+		base.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	}
+	if cfg.IdleConnTimeout > 0 {
+		base.IdleConnTimeout = cfg.IdleConnTimeout
+	}
+	base.MaxConnsPerHost = cfg.MaxConnsPerHost
+	base.DisableKeepAlives = cfg.DisableKeepAlives
+
+	endpoint := cfg.Endpoint
+	if cfg.UnixSocket != "" {
+		if endpoint == "" {
+			endpoint = "http://sentry-relay"
+		}
+		// Only the socket endpoint is dialed over the socket (and never
+		// proxied), so failover endpoints can route around it
+		socket := cfg.UnixSocket
+		socketAddr := dialAddress(endpoint)
+		proxy := base.Proxy
+		base.Proxy = func(req *http.Request) (*url.URL, error) {
+			if proxy == nil || dialAddress(req.URL.String()) == socketAddr {
+				return nil, nil
+			}
+			return proxy(req)
+		}
+		base.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			var dialer net.Dialer
+			if addr == socketAddr {
+				return dialer.DialContext(ctx, "unix", socket)
+			}
+			return dialer.DialContext(ctx, network, addr)
+		}
+	}
+
+	var transport http.RoundTripper = base
+	if len(cfg.Failover) > 0 {
+		// Validated by Config.Validate
+		primary := &failoverEndpoint{name: "primary"}
+		if endpoint != "" {
+			primary.base, _ = url.Parse(endpoint)
+			primary.name = primary.base.Host
+		}
+		failover := &failoverTransport{
+			base:      transport,
+			endpoints: []*failoverEndpoint{primary},
+			cooldown:  cfg.FailoverCooldown,
+		}
+		if failover.cooldown <= 0 {
+			failover.cooldown = defaultFailoverCooldown
+		}
+		for _, raw := range cfg.Failover {
+			secondary, _ := parseFailoverEndpoint(raw)
+			failover.endpoints = append(failover.endpoints, secondary)
+		}
+		transport = failover
+	} else if endpoint != "" {
+		// Validated by Config.Validate
+		u, _ := url.Parse(endpoint)
+		transport = &endpointTransport{base: transport, endpoint: u}
+	}
+	if cfg.Compression != CompressionNone {
+		transport = &compressingTransport{base: transport, encoding: cfg.Compression}
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   cfg.RequestTimeout,
+	}, nil
+}
+
+// tlsConfig builds the TLS settings, or returns nil for the defaults
+func (cfg TransportConfig) tlsConfig() (*tls.Config, error) {
+	if cfg.TLSClientCert == "" && cfg.TLSRootCA == "" && !cfg.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// Guarded by Config.DevMode in Config.Validate
+		InsecureSkipVerify: cfg.InsecureSkipVerify, //nolint:gosec
+	}
+
+	if cfg.TLSClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSClientCert, cfg.TLSClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("loading TLS client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if cfg.TLSRootCA != "" {
+		pem, err := os.ReadFile(cfg.TLSRootCA)
+		if err != nil {
+			return nil, fmt.Errorf("reading TLS root CA: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in TLS root CA %s", cfg.TLSRootCA)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
+// dialAddress returns the host:port dialed for requests to a base URL
+func dialAddress(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	if u.Port() != "" {
+		return u.Host
+	}
+	if u.Scheme == "https" {
+		return net.JoinHostPort(u.Hostname(), "443")
+	}
+	return net.JoinHostPort(u.Hostname(), "80")
+}
+
+// endpointTransport redirects requests to a fixed endpoint
+type endpointTransport struct {
+	base     http.RoundTripper
+	endpoint *url.URL
+}
+
+// RoundTrip replaces the scheme and host of the request URL and prepends
+// the endpoint path
+func (t *endpointTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.endpoint.Scheme
+	req.URL.Host = t.endpoint.Host
+	req.URL.Path = strings.TrimSuffix(t.endpoint.Path, "/") + req.URL.Path
+	req.URL.RawPath = ""
+	req.Host = t.endpoint.Host
+	return t.base.RoundTrip(req)
+}
+
+// compressingTransport compresses request bodies before sending them
+type compressingTransport struct {
+	base     http.RoundTripper
+	encoding string
+}
+
+// RoundTrip compresses the body and sets Content-Encoding
+func (t *compressingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil || req.Header.Get("Content-Encoding") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	compressed, err := compress(body, t.encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	// RoundTrippers must not modify the original request
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(compressed))
+	req.ContentLength = int64(len(compressed))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(compressed)), nil
+	}
+	req.Header.Set("Content-Encoding", t.encoding)
+
+	return t.base.RoundTrip(req)
+}
+
+// compress encodes data with the given algorithm
+func compress(data []byte, encoding string) ([]byte, error) {
+	var buf bytes.Buffer
+	switch encoding {
+	case CompressionZstd:
+		w, err := zstd.NewWriter(&buf)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+	default:
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,91 @@
+package sentrykit
+
+import (
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+	"github.com/valyala/fasthttp"
+)
+
+// warnedUninitialized logs the missing client once per process
+var warnedUninitialized sync.Once
+
+// warnUninitialized logs that nothing is reported because Sentry was never
+// initialized
+func warnUninitialized() {
+	warnedUninitialized.Do(func() {
+		log.Print("sentrykit: Sentry is not initialized (see sentrykit.Init); requests and context helpers are not reported")
+	})
+}
+
+// clientBound reports whether the middleware has a client to report to.
+// It is checked per request, as Init may run after the middleware is built.
+func clientBound(cfg MiddlewareConfig) bool {
+	if cfg.Client != nil || sentry.CurrentHub().Client() != nil {
+		return true
+	}
+	warnUninitialized()
+	return false
+}
+
+// activeHub returns the hub of the request, or nil when it has no client,
+// so the context helpers skip scope work whose events would go nowhere
+func activeHub(c fiber.Ctx) *sentry.Hub {
+	hub := GetHubFromContext(c)
+	if hub.Client() == nil {
+		warnUninitialized()
+		return nil
+	}
+	return hub
+}
+
+// serveUnreported runs a Fiber request without Sentry. Panics still become
+// 500 responses (or PanicHandler), or are re-raised with Repanic, so the
+// server behaves as with a client.
+func serveUnreported(c fiber.Ctx, cfg MiddlewareConfig) error {
+	defer func() {
+		if err := recover(); err != nil {
+			if cfg.Repanic {
+				panic(err)
+			}
+			if cfg.PanicHandler != nil {
+				_ = cfg.PanicHandler(c, nil)
+			} else {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+	}()
+	return c.Next()
+}
+
+// serveHTTPUnreported is serveUnreported for net/http
+func serveHTTPUnreported(next http.Handler, w http.ResponseWriter, r *http.Request, cfg MiddlewareConfig) {
+	rw := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	defer func() {
+		if err := recover(); err != nil {
+			if cfg.Repanic {
+				panic(err)
+			}
+			if !rw.wroteHeader {
+				rw.WriteHeader(http.StatusInternalServerError)
+			}
+		}
+	}()
+	next.ServeHTTP(rw, r)
+}
+
+// serveFastHTTPUnreported is serveUnreported for fasthttp
+func serveFastHTTPUnreported(next fasthttp.RequestHandler, ctx *fasthttp.RequestCtx, cfg MiddlewareConfig) {
+	defer func() {
+		if err := recover(); err != nil {
+			if cfg.Repanic {
+				panic(err)
+			}
+			ctx.Error(fasthttp.StatusMessage(fasthttp.StatusInternalServerError), fasthttp.StatusInternalServerError)
+		}
+	}()
+	next(ctx)
+}
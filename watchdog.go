@@ -0,0 +1,217 @@
+package sentrykit
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+)
+
+// maxGoroutineDump caps the goroutine dump attached to diagnostic events
+const maxGoroutineDump = 4 << 20
+
+// StallConfig configures stall detection
+type StallConfig struct {
+	// Threshold is how far a scheduler check may run late, or how long a
+	// monitored handler may run, before a stall is reported (default: 1s)
+	Threshold time.Duration
+
+	// Interval between scheduler checks (default: 100ms)
+	Interval time.Duration
+
+	// Cooldown is the minimum time between two reports (default: 1m)
+	Cooldown time.Duration
+}
+
+// withDefaults fills in unset stall settings
+func (cfg StallConfig) withDefaults() StallConfig {
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = time.Second
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 100 * time.Millisecond
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = time.Minute
+	}
+	return cfg
+}
+
+// StartStallDetector starts a watchdog goroutine that reports when the
+// scheduler is blocked beyond the threshold, e.g. by long GC pauses or
+// goroutines starving the runtime, similar to mobile ANR detection. The
+// warning event carries a "stall" context and a dump of all goroutines.
+// Call the returned function to stop.
+func StartStallDetector(config ...StallConfig) (stop func()) {
+	var cfg StallConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	cfg = cfg.withDefaults()
+
+	limiter := &cooldown{interval: cfg.Cooldown}
+	last := time.Now()
+
+	return startTicker(cfg.Interval, func() {
+		now := time.Now()
+		lateness := now.Sub(last) - cfg.Interval
+		last = now
+
+		if lateness < cfg.Threshold || !limiter.allow() {
+			return
+		}
+
+		var mem runtime.MemStats
+		runtime.ReadMemStats(&mem)
+
+		captureDiagnostic(sentry.CurrentHub(), diagnostic{
+			message:     fmt.Sprintf("Scheduler stalled for %s", lateness.Round(time.Millisecond)),
+			fingerprint: []string{"stall", "scheduler"},
+			context: map[string]interface{}{
+				"stalled_ms":       milliseconds(lateness),
+				"threshold_ms":     milliseconds(cfg.Threshold),
+				"last_gc_pause_ms": milliseconds(time.Duration(mem.PauseNs[(mem.NumGC+255)%256])),
+				"num_gc":           mem.NumGC,
+				"goroutines":       runtime.NumGoroutine(),
+			},
+			attachments: []*sentry.Attachment{goroutineDump()},
+		})
+
+		// Don't count the time spent reporting as the next stall
+		last = time.Now()
+	})
+}
+
+// MonitorHandler reports a handler still running after the threshold, e.g.
+// blocked on a lock convoy or a stuck dependency, while it is still
+// blocked. The warning event carries the request scope's data as it was
+// when the handler started and a dump of all goroutines. The handler itself
+// is not interrupted.
+func MonitorHandler(handler fiber.Handler, config ...StallConfig) fiber.Handler {
+	var cfg StallConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	cfg = cfg.withDefaults()
+
+	limiter := &cooldown{interval: cfg.Cooldown}
+
+	return func(c fiber.Ctx) error {
+		// The timer fires on another goroutine while the handler still uses
+		// the request, so capture what it needs now
+		hub := detachedHub(GetHubFromContext(c))
+		route := c.Route().Path
+		method := strings.Clone(c.Method())
+		start := now()
+
+		timer := time.AfterFunc(cfg.Threshold, func() {
+			if !limiter.allow() {
+				return
+			}
+			captureDiagnostic(hub, diagnostic{
+				message:     fmt.Sprintf("Handler %s %s blocked for over %s", method, route, cfg.Threshold),
+				fingerprint: []string{"stall", "handler", route},
+				context: map[string]interface{}{
+					"route":        route,
+					"method":       method,
+					"blocked_ms":   milliseconds(since(start)),
+					"threshold_ms": milliseconds(cfg.Threshold),
+					"goroutines":   runtime.NumGoroutine(),
+				},
+				attachments: []*sentry.Attachment{goroutineDump()},
+			})
+		})
+		defer timer.Stop()
+
+		return handler(c)
+	}
+}
+
+// detachedHub returns a hub on a fresh scope holding a copy of the data of
+// hub's scope, for reports from other goroutines. The request scope's
+// processors read the live request, so they are left behind.
+func detachedHub(hub *sentry.Hub) *sentry.Hub {
+	scope := sentry.NewScope()
+	// A transaction keeps the request processors from attaching anything
+	if event := hub.Scope().ApplyToEvent(&sentry.Event{Type: "transaction"}, nil, nil); event != nil {
+		scope.SetTags(event.Tags)
+		scope.SetExtras(event.Extra)
+		scope.SetUser(event.User)
+		for key, value := range event.Contexts {
+			if key != "trace" {
+				scope.SetContext(key, value)
+			}
+		}
+		for _, breadcrumb := range event.Breadcrumbs {
+			scope.AddBreadcrumb(breadcrumb, len(event.Breadcrumbs))
+		}
+	}
+	return sentry.NewHub(hub.Client(), scope)
+}
+
+// diagnostic is a watchdog finding reported as a warning event
+type diagnostic struct {
+	message     string
+	fingerprint []string
+	context     map[string]interface{}
+	attachments []*sentry.Attachment
+}
+
+// captureDiagnostic reports a watchdog finding on hub
+func captureDiagnostic(hub *sentry.Hub, d diagnostic) {
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetLevel(sentry.LevelWarning)
+		scope.SetTag("watchdog", d.fingerprint[0])
+		scope.SetFingerprint(d.fingerprint)
+		scope.SetContext(d.fingerprint[0], d.context)
+		for _, attachment := range d.attachments {
+			scope.AddAttachment(attachment)
+		}
+		hub.CaptureMessage(d.message)
+	})
+}
+
+// goroutineDump returns the stacks of all goroutines as an attachment,
+// truncated to maxGoroutineDump
+func goroutineDump() *sentry.Attachment {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= maxGoroutineDump {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	return &sentry.Attachment{
+		Filename:    "goroutines.txt",
+		ContentType: "text/plain",
+		Payload:     buf,
+	}
+}
+
+// cooldown allows an action at most once per interval
+type cooldown struct {
+	interval time.Duration
+	last     atomic.Int64
+}
+
+// allow reports whether the interval passed since the last allowed action
+func (c *cooldown) allow() bool {
+	current := now().UnixNano()
+	last := c.last.Load()
+	if last != 0 && time.Duration(current-last) < c.interval {
+		return false
+	}
+	return c.last.CompareAndSwap(last, current)
+}
+
+// milliseconds converts a duration to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,120 @@
+package sentrykit
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestMonitorHandlerReportsWhileHandlerSetsTags(t *testing.T) {
+	transport := bindTestClient(t)
+
+	// Hold the stall report mid-capture until the handler changed its scope
+	reporting := make(chan struct{})
+	tagged := make(chan struct{})
+	var hold sync.Once
+	sentry.CurrentHub().Client().AddEventProcessor(func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+		if event.Tags["watchdog"] == "stall" {
+			hold.Do(func() {
+				close(reporting)
+				<-tagged
+			})
+		}
+		return event
+	})
+
+	app := fiber.New()
+	app.Use(New())
+	app.Use(func(c fiber.Ctx) error {
+		SetTagFromContext(c, "phase", "started")
+		return c.Next()
+	})
+	app.Get("/slow", MonitorHandler(func(c fiber.Ctx) error {
+		select {
+		case <-reporting:
+		case <-time.After(2 * time.Second):
+			close(tagged)
+			return errors.New("blocked handler not reported")
+		}
+		SetTagFromContext(c, "phase", "reported")
+		close(tagged)
+		return errors.New("slow handler failed")
+	}, StallConfig{Threshold: 20 * time.Millisecond}))
+
+	if _, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/slow", nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	stall := waitForEvent(transport, time.Second, func(event *sentry.Event) bool {
+		return event.Tags["watchdog"] == "stall"
+	})
+	if stall == nil {
+		t.Fatal("blocked handler not reported")
+	}
+	if stall.Tags["phase"] != "started" {
+		t.Errorf("report tags = %v, want the request scope from before the handler", stall.Tags)
+	}
+
+	failure := waitForEvent(transport, time.Second, func(event *sentry.Event) bool {
+		return len(event.Exception) > 0
+	})
+	if failure == nil {
+		t.Fatal("handler error not captured")
+	}
+	if failure.Tags["phase"] != "reported" || failure.Tags["watchdog"] != "" {
+		t.Errorf("handler error tags = %v, want the handler's own scope", failure.Tags)
+	}
+}
+
+func TestMonitorHandlerLeavesRequestProcessorsBehind(t *testing.T) {
+	transport := bindTestClient(t)
+
+	reported := make(chan struct{})
+	var once sync.Once
+	sentry.CurrentHub().Client().AddEventProcessor(func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+		if event.Tags["watchdog"] == "stall" {
+			once.Do(func() { close(reported) })
+		}
+		return event
+	})
+
+	app := fiber.New()
+	app.Use(New(MiddlewareConfig{ReplayBundle: true, CurlCommand: true}))
+	app.Post("/slow", MonitorHandler(func(c fiber.Ctx) error {
+		// Keep using the request while the report is built
+		deadline := time.After(2 * time.Second)
+		for {
+			select {
+			case <-reported:
+				return c.SendString("done")
+			case <-deadline:
+				return c.SendString("timeout")
+			default:
+				c.Set("X-Progress", string(c.Body()))
+			}
+		}
+	}, StallConfig{Threshold: 20 * time.Millisecond}))
+
+	req := httptest.NewRequest(fiber.MethodPost, "/slow", strings.NewReader("payload"))
+	if _, err := app.Test(req); err != nil {
+		t.Fatal(err)
+	}
+
+	stall := waitForEvent(transport, time.Second, func(event *sentry.Event) bool {
+		return event.Tags["watchdog"] == "stall"
+	})
+	if stall == nil {
+		t.Fatal("blocked handler not reported")
+	}
+	for _, attachment := range stall.Attachments {
+		if attachment.Filename != "goroutines.txt" {
+			t.Errorf("stall report carries request attachment %q", attachment.Filename)
+		}
+	}
+}